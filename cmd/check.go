@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/check"
+)
+
+var (
+	// checkCmd runs the validation the apiserver runs when a CRD is created.
+	checkCmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check that CRD schemas would be accepted by the apiserver.",
+		Long: `Check that CRD schemas would be accepted by the apiserver.
+
+Every version is checked for structural schema errors, invalid defaults and
+CEL rules that don't compile or exceed the cost budget. The estimated cost of
+every CEL rule is displayed as well. The command exits with a non-zero exit code
+if any error is found.`,
+		SilenceUsage: true,
+		RunE:         runCheck,
+	}
+
+	checkArgs = &checkCmdArgs{}
+)
+
+type checkCmdArgs struct {
+	source rootArgs
+	output string
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	f := checkCmd.PersistentFlags()
+	addSourceFlags(f, &checkArgs.source)
+	f.StringVarP(&checkArgs.output, "output", "o", OutputText, "The format of the report. Options are: text, json.")
+}
+
+func runCheck(cmd *cobra.Command, _ []string) (err error) {
+	crdHandler, err := constructHandler(&checkArgs.source)
+	if err != nil {
+		return err
+	}
+
+	crds, partial, err := loadCRDs(crdHandler)
+	if err != nil {
+		return fmt.Errorf("failed to load CRDs: %w", err)
+	}
+
+	defer func() {
+		err = reportPartialLoad(partial, err)
+	}()
+
+	result, err := check.Check(cmd.Context(), crds)
+	if err != nil {
+		return err
+	}
+
+	switch checkArgs.output {
+	case OutputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+	case OutputText:
+		displayCheckResult(os.Stdout, result)
+	default:
+		return fmt.Errorf("unknown output format %s", checkArgs.output)
+	}
+
+	if len(result.Problems) > 0 {
+		return fmt.Errorf("%d error(s) found", len(result.Problems))
+	}
+
+	return nil
+}
+
+func displayCheckResult(w io.Writer, result *check.Result) {
+	if len(result.Costs) > 0 {
+		t := table.NewWriter()
+		t.SetOutputMirror(w)
+		t.AppendHeader(table.Row{"Kind", "Version", "Path", "Rule", "Estimated Cost"})
+		rows := make([]table.Row, 0, len(result.Costs))
+		for _, c := range result.Costs {
+			rows = append(rows, table.Row{c.Group + "/" + c.Kind, c.Version, c.Path, text.WrapText(c.Rule, wrapLen), c.Cost})
+		}
+		t.AppendRows(rows)
+		t.Render()
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if len(result.Problems) == 0 {
+		_, _ = fmt.Fprintln(w, "No problems found.")
+
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Kind", "Version", "Path", "Error"})
+	rows := make([]table.Row, 0, len(result.Problems))
+	for _, p := range result.Problems {
+		rows = append(rows, table.Row{
+			p.Group + "/" + p.Kind, p.Version, color.RedString(p.Path), text.WrapText(p.Message, wrapLen),
+		})
+	}
+	t.AppendRows(rows)
+	t.Render()
+
+	_, _ = fmt.Fprintf(w, "\nErrors total: %d\n", len(result.Problems))
+}
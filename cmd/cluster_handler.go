@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cluster"
+)
+
+// ClusterHandler discovers the CRDs installed in a cluster.
+type ClusterHandler struct {
+	// kubeconfig is the path of the kubeconfig file. If empty, the default kubeconfig is loaded.
+	kubeconfig    string
+	context       string
+	groups        []string
+	labelSelector string
+}
+
+func (h *ClusterHandler) CRDs() ([]*pkg.SchemaType, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = h.kubeconfig
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{
+		CurrentContext: h.context,
+	}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	client, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	crds, err := cluster.CRDs(context.Background(), client.ApiextensionsV1().CustomResourceDefinitions(), cluster.Options{
+		Groups:        h.groups,
+		LabelSelector: h.labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, "Discovered number of CRDs: ", len(crds))
+
+	return crds, nil
+}
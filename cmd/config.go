@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// configCmd is root for various `config ...` commands.
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Work with config files.",
+	}
+
+	// configValidateCmd validates config files.
+	configValidateCmd = &cobra.Command{
+		Use:   "validate config-file...",
+		Short: "Validate config files without loading their sources.",
+		Long: `Validate config files without loading their sources.
+
+Unknown fields, values of the wrong type and invalid options are reported with the line they are defined on.
+The JSON schema of the config file is published as config.schema.json in the repository and can be used by
+editors to complete and check the file while it's written.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runConfigValidate,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
+
+func runConfigValidate(_ *cobra.Command, files []string) error {
+	var failed int
+	for _, file := range files {
+		if _, err := loadConfig(file); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(os.Stderr, "%s: invalid config file:\n%s\n", file, err)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "%s: valid\n", file)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d config files are invalid", failed, len(files))
+	}
+
+	return nil
+}
@@ -1,33 +1,118 @@
 package cmd
 
+// URLs is a URL CRDs are fetched from.
 type URLs struct {
-	URL      string `json:"url"`
+	// URL of a file with one or more CRDs. GitHub and GitLab blob and tree URLs are supported.
+	URL string `json:"url"`
+	// Username and Password authenticate with basic auth.
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
-	Token    string `json:"token,omitempty"`
+	// Token is a bearer token.
+	Token string `json:"token,omitempty"`
+	// Headers are added to the request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// CABundle is the name of a file with additional certificates to trust.
+	CABundle string `json:"caBundle,omitempty"`
+	// ClientCert and ClientKey are the names of the files of a client certificate used for mutual TLS.
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// Proxy is the URL of the proxy requests are sent through. Defaults to the proxy of the environment.
+	Proxy string `json:"proxy,omitempty"`
+	// Timeout is a duration like 30s. Defaults to 10s.
+	Timeout string `json:"timeout,omitempty"`
+	// Retries of requests failing with a 5xx or 429 status code. Defaults to 3.
+	Retries *int `json:"retries,omitempty"`
 }
 
+// GITUrls is a git repository CRDs are discovered in.
 type GITUrls struct {
-	URL         string `json:"url"`
-	Username    string `json:"username,omitempty"`
-	Password    string `json:"password,omitempty"`
-	Token       string `json:"token,omitempty"`
-	Tag         string `json:"tag,omitempty"`
-	PrivateKey  string `json:"privateKey,omitempty"`
-	UseSSHAgent bool   `json:"useSSHAgent,omitempty"`
+	// URL of the repository.
+	URL string `json:"url"`
+	// Username and Password authenticate with basic auth.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Token is sent as a bearer token in the Authorization header.
+	Token string `json:"token,omitempty"`
+	// Tag to check out. Default is head.
+	Tag string `json:"tag,omitempty"`
+	// PrivateKey is the name of the file of the private key used for cloning over SSH.
+	PrivateKey string `json:"privateKey,omitempty"`
+	// UseSSHAgent clones with the configured SSH agent.
+	UseSSHAgent bool `json:"useSSHAgent,omitempty"`
+	// Ref is a branch or a commit SHA to check out.
+	Ref string `json:"ref,omitempty"`
+	// Path limits the discovery to a directory of the repository.
+	Path string `json:"path,omitempty"`
+	// Include and Exclude are glob patterns like config/crd/** that select the files CRDs are discovered in.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	// IncludeTests includes files in test directories, which are skipped by default.
+	IncludeTests bool `json:"includeTests,omitempty"`
+}
+
+// OCIUrls is an OCI artifact CRDs are loaded from.
+type OCIUrls struct {
+	// URL is the reference of the artifact like ghcr.io/org/crds:v1.0.0.
+	URL string `json:"url"`
+	// Username and Password authenticate with the registry.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Token is a registry access token.
+	Token string `json:"token,omitempty"`
+	// PlainHTTP connects to the registry with HTTP instead of HTTPS.
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+}
+
+// Filter selects names. A name is selected if it matches one of the Include patterns, or there are none,
+// and it doesn't match any of the Exclude patterns. Patterns like v1alpha* are supported.
+type Filter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
 }
 
 // APIGroups defines groups by which grouping will happen in the resulting HTML output.
 type APIGroups struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	Files       []string  `json:"files,omitempty"`
-	Folders     []string  `json:"folders,omitempty"`
-	URLs        []URLs    `json:"urls,omitempty"`
-	GitURLs     []GITUrls `json:"gitUrls,omitempty"`
+	// Name of the group. If empty, the group of the CRDs is used.
+	Name string `json:"name,omitempty"`
+	// Description of the group.
+	Description string `json:"description,omitempty"`
+	// Logo is the URL of an image shown next to the name of the group.
+	Logo string `json:"logo,omitempty"`
+	// Link is the URL of a page the name of the group links to.
+	Link string `json:"link,omitempty"`
+	// Order is the position of the group in the output. Groups are rendered in ascending order and groups
+	// with the same order in the order they are defined in.
+	Order int `json:"order,omitempty"`
+	// Files, Folders and Charts are local files, folders and Helm charts the CRDs are loaded from.
+	Files   []string `json:"files,omitempty"`
+	Folders []string `json:"folders,omitempty"`
+	Charts  []string `json:"charts,omitempty"`
+	// URLs are fetched over HTTP.
+	URLs []URLs `json:"urls,omitempty"`
+	// GitURLs are git repositories the CRDs are discovered in.
+	GitURLs []GITUrls `json:"gitUrls,omitempty"`
+	// OCIURLs are OCI artifacts the CRDs are loaded from.
+	OCIURLs []OCIUrls `json:"ociUrls,omitempty"`
+
+	// Minimal, Comments and NoRandom override the flags of the same name for the CRDs of the group.
+	Minimal  *bool `json:"minimal,omitempty"`
+	Comments *bool `json:"comments,omitempty"`
+	NoRandom *bool `json:"noRandom,omitempty"`
+	// Kinds and Versions select the kinds and versions of the group that are rendered.
+	Kinds    Filter `json:"kinds,omitempty"`
+	Versions Filter `json:"versions,omitempty"`
+	// HideDeprecated hides versions that are marked as deprecated.
+	HideDeprecated bool `json:"hideDeprecated,omitempty"`
+	// Sort is the order of the kinds of the group. Options are: source, kind. Default is source,
+	// which keeps the order in which they are loaded.
+	Sort string `json:"sort,omitempty"`
+	// Output is the location the group is rendered to instead of the output flag. It's a folder
+	// for YAML and site and a file for HTML.
+	Output string `json:"output,omitempty"`
 }
 
 // RenderConfig defines a configuration for the resulting rendered HTML content.
 type RenderConfig struct {
+	// APIGroups are the groups the CRDs are rendered in.
 	APIGroups []APIGroups `json:"apiGroups"`
 }
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/glob"
+)
+
+const (
+	SortSource = "source"
+	SortKind   = "kind"
+)
+
+// validate checks the options of the group before any of its sources are loaded.
+func (g *APIGroups) validate() error {
+	var errs []error
+	switch g.Sort {
+	case "", SortSource, SortKind:
+	default:
+		errs = append(errs, fmt.Errorf("unknown sort order %s of group %s, options are: %s, %s", g.Sort, g.Name, SortSource, SortKind))
+	}
+
+	for _, filter := range []Filter{g.Kinds, g.Versions} {
+		for _, pattern := range slices.Concat(filter.Include, filter.Exclude) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("invalid pattern %s of group %s: %w", pattern, g.Name, err))
+			}
+		}
+	}
+
+	for i, url := range g.URLs {
+		if url.URL == "" {
+			errs = append(errs, fmt.Errorf("url %d of group %s has no url", i, g.Name))
+		}
+
+		if _, err := url.httpOptions(); err != nil {
+			errs = append(errs, fmt.Errorf("url %d of group %s has invalid options: %w", i, g.Name, err))
+		}
+	}
+
+	for i, url := range g.GitURLs {
+		if url.URL == "" {
+			errs = append(errs, fmt.Errorf("git url %d of group %s has no url", i, g.Name))
+		}
+
+		for _, patterns := range [][]string{url.Include, url.Exclude} {
+			if _, err := glob.CompileAll(patterns); err != nil {
+				errs = append(errs, fmt.Errorf("git url %d of group %s has an invalid pattern: %w", i, g.Name, err))
+			}
+		}
+	}
+
+	for i, url := range g.OCIURLs {
+		if url.URL == "" {
+			errs = append(errs, fmt.Errorf("oci url %d of group %s has no url", i, g.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// apply filters and sorts the CRDs loaded for the group and sets the options they are rendered with.
+func (g *APIGroups) apply(crds []*pkg.SchemaType) []*pkg.SchemaType {
+	result := make([]*pkg.SchemaType, 0, len(crds))
+	for _, crd := range crds {
+		if !g.Kinds.matches(crd.Kind) {
+			continue
+		}
+
+		if len(crd.Versions) > 0 {
+			crd.Versions = slices.DeleteFunc(crd.Versions, func(v *pkg.CRDVersion) bool {
+				return !g.Versions.matches(v.Name) || (g.HideDeprecated && v.Deprecated)
+			})
+
+			if len(crd.Versions) == 0 {
+				continue
+			}
+		} else if crd.Validation != nil && !g.Versions.matches(crd.Validation.Name) {
+			// CRDs with a validation instead of versions are kept if the name of the validation matches.
+			continue
+		}
+
+		crd.Rendering.Description = g.Description
+		crd.Rendering.Logo = g.Logo
+		crd.Rendering.Link = g.Link
+		crd.Rendering.Order = g.Order
+		crd.Rendering.Minimal = g.Minimal
+		crd.Rendering.Comments = g.Comments
+		crd.Rendering.SkipRandom = g.NoRandom
+		crd.Rendering.Output = g.Output
+
+		result = append(result, crd)
+	}
+
+	if g.Sort == SortKind {
+		slices.SortStableFunc(result, func(a, b *pkg.SchemaType) int {
+			return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Group, b.Group))
+		})
+	}
+
+	return result
+}
+
+// matches returns true if the filter selects name.
+func (f Filter) matches(name string) bool {
+	matches := func(pattern string) bool {
+		ok, _ := path.Match(pattern, name)
+
+		return ok
+	}
+
+	if len(f.Include) > 0 && !slices.ContainsFunc(f.Include, matches) {
+		return false
+	}
+
+	return !slices.ContainsFunc(f.Exclude, matches)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestFilterMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "no patterns", filter: Filter{}, want: []string{"v1", "v1beta1", "v2alpha1"}},
+		{name: "include", filter: Filter{Include: []string{"v1*"}}, want: []string{"v1", "v1beta1"}},
+		{name: "exclude", filter: Filter{Exclude: []string{"*alpha*"}}, want: []string{"v1", "v1beta1"}},
+		{name: "exclude wins over include", filter: Filter{Include: []string{"v*"}, Exclude: []string{"v1"}}, want: []string{"v1beta1", "v2alpha1"}},
+		{name: "exact names", filter: Filter{Include: []string{"v1", "v2alpha1"}}, want: []string{"v1", "v2alpha1"}},
+		{name: "invalid pattern matches nothing", filter: Filter{Include: []string{"[v1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, name := range []string{"v1", "v1beta1", "v2alpha1"} {
+				if tt.filter.matches(name) {
+					got = append(got, name)
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPIGroupsApply(t *testing.T) {
+	crds := func() []*pkg.SchemaType {
+		return []*pkg.SchemaType{
+			{Group: "b.example.com", Kind: "Widget", Versions: []*pkg.CRDVersion{
+				{Name: "v1"}, {Name: "v1beta1", Deprecated: true}, {Name: "v2alpha1"},
+			}},
+			{Group: "a.example.com", Kind: "Widget", Versions: []*pkg.CRDVersion{{Name: "v1alpha1"}}},
+			{Group: "a.example.com", Kind: "Gadget", Versions: []*pkg.CRDVersion{{Name: "v1"}}},
+			{Group: "a.example.com", Kind: "Legacy", Validation: &pkg.Validation{Name: "v1beta1"}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		group    APIGroups
+		kinds    []string
+		versions map[string][]string
+	}{
+		{
+			name:  "everything",
+			kinds: []string{"b.example.com/Widget", "a.example.com/Widget", "a.example.com/Gadget", "a.example.com/Legacy"},
+		},
+		{
+			name:  "kinds",
+			group: APIGroups{Kinds: Filter{Include: []string{"W*", "Legacy"}, Exclude: []string{"Legacy"}}},
+			kinds: []string{"b.example.com/Widget", "a.example.com/Widget"},
+		},
+		{
+			name:     "versions drop CRDs without versions left",
+			group:    APIGroups{Versions: Filter{Exclude: []string{"*alpha*"}}},
+			kinds:    []string{"b.example.com/Widget", "a.example.com/Gadget", "a.example.com/Legacy"},
+			versions: map[string][]string{"b.example.com/Widget": {"v1", "v1beta1"}},
+		},
+		{
+			name:  "validation is matched by its name",
+			group: APIGroups{Versions: Filter{Include: []string{"v1"}}},
+			kinds: []string{"b.example.com/Widget", "a.example.com/Gadget"},
+		},
+		{
+			name:     "hide deprecated",
+			group:    APIGroups{HideDeprecated: true},
+			kinds:    []string{"b.example.com/Widget", "a.example.com/Widget", "a.example.com/Gadget", "a.example.com/Legacy"},
+			versions: map[string][]string{"b.example.com/Widget": {"v1", "v2alpha1"}},
+		},
+		{
+			name:  "sort by kind and group",
+			group: APIGroups{Sort: SortKind},
+			kinds: []string{"a.example.com/Gadget", "a.example.com/Legacy", "a.example.com/Widget", "b.example.com/Widget"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.group.apply(crds())
+
+			var kinds []string
+			for _, crd := range result {
+				key := crd.Group + "/" + crd.Kind
+				kinds = append(kinds, key)
+
+				if want, ok := tt.versions[key]; ok {
+					var versions []string
+					for _, v := range crd.Versions {
+						versions = append(versions, v.Name)
+					}
+
+					assert.Equal(t, want, versions, key)
+				}
+			}
+
+			assert.Equal(t, tt.kinds, kinds)
+		})
+	}
+}
+
+func TestAPIGroupsApplyRendering(t *testing.T) {
+	minimal, noRandom := true, false
+	group := APIGroups{
+		Description: "Widgets",
+		Logo:        "https://example.com/logo.png",
+		Link:        "https://example.com",
+		Order:       2,
+		Minimal:     &minimal,
+		NoRandom:    &noRandom,
+		Output:      "widgets",
+	}
+
+	result := group.apply([]*pkg.SchemaType{{Kind: "Widget", Versions: []*pkg.CRDVersion{{Name: "v1"}}}})
+	require.Len(t, result, 1)
+	assert.Equal(t, pkg.Rendering{
+		Description: "Widgets",
+		Logo:        "https://example.com/logo.png",
+		Link:        "https://example.com",
+		Order:       2,
+		Minimal:     &minimal,
+		SkipRandom:  &noRandom,
+		Output:      "widgets",
+	}, result[0].Rendering)
+}
+
+func TestAPIGroupsValidate(t *testing.T) {
+	assert.NoError(t, (&APIGroups{
+		Name:    "valid",
+		Sort:    SortKind,
+		Kinds:   Filter{Include: []string{"W*"}},
+		URLs:    []URLs{{URL: "https://example.com/crd.yaml", Timeout: "30s"}},
+		GitURLs: []GITUrls{{URL: "https://example.com/repo.git", Include: []string{"config/**"}}},
+	}).validate())
+
+	err := (&APIGroups{
+		Name:     "invalid",
+		Sort:     "name",
+		Versions: Filter{Exclude: []string{"[v1"}},
+		URLs:     []URLs{{Timeout: "soon"}},
+		GitURLs:  []GITUrls{{URL: "https://example.com/repo.git", Exclude: []string{"crds/[a-.yaml"}}, {}},
+		OCIURLs:  []OCIUrls{{}},
+	}).validate()
+	require.Error(t, err)
+
+	for _, message := range []string{
+		"unknown sort order name of group invalid",
+		"invalid pattern [v1 of group invalid",
+		"url 0 of group invalid has no url",
+		"url 0 of group invalid has invalid options",
+		"git url 0 of group invalid has an invalid pattern",
+		"git url 1 of group invalid has no url",
+		"oci url 0 of group invalid has no url",
+	} {
+		assert.ErrorContains(t, err, message)
+	}
+}
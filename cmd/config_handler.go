@@ -1,92 +1,358 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
-	"k8s.io/apimachinery/pkg/util/yaml"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/strict"
 )
 
 type ConfigHandler struct {
 	configFileLocation string
+	cache              *cache.Cache
+	// concurrency is the number of sources loaded at the same time.
+	concurrency int
+	// continueOnError builds the result from the sources that could be loaded instead of failing
+	// on the first source that couldn't.
+	continueOnError bool
 }
 
 func (h *ConfigHandler) CRDs() ([]*pkg.SchemaType, error) {
-	if _, err := os.Stat(h.configFileLocation); os.IsNotExist(err) {
-		return nil, fmt.Errorf("file under '%s' does not exist", h.configFileLocation)
+	configFile, err := loadConfig(h.configFileLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configFile.expandEnv(); err != nil {
+		return nil, err
+	}
+
+	sources, err := h.sources(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	results, errs := h.load(sources)
+
+	var failed []error
+	for i, s := range sources {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Errorf("failed to process CRDs for %s: %w", s.description, errs[i]))
+		}
+	}
+
+	// the sources of a group are next to each other, so the CRDs of a group can be collected in one pass.
+	var result []*pkg.SchemaType
+	for i := 0; i < len(sources); {
+		group := sources[i].group
+
+		var crds []*pkg.SchemaType
+		for ; i < len(sources) && sources[i].group == group; i++ {
+			crds = append(crds, results[i]...)
+		}
+
+		result = append(result, group.apply(crds)...)
+	}
+
+	if len(failed) == 0 {
+		return result, nil
+	}
+
+	if !h.continueOnError {
+		return nil, failed[0]
+	}
+
+	if len(failed) == len(sources) {
+		printFailures(os.Stderr, sources, errs)
+
+		return nil, errors.Join(failed...)
+	}
+
+	return result, &PartialLoadError{sources: sources, errs: errs, failed: len(failed)}
+}
+
+// PartialLoadError is returned with the CRDs of the sources that could be loaded if others failed and
+// continueOnError is set. The summary of the failed sources is printed once the output is rendered.
+type PartialLoadError struct {
+	sources []source
+	errs    []error
+	failed  int
+}
+
+func (e *PartialLoadError) Error() string {
+	return fmt.Sprintf("failed to load %d of %d sources", e.failed, len(e.sources))
+}
+
+// Print prints a summary of the sources that failed to load.
+func (e *PartialLoadError) Print(w io.Writer) {
+	printFailures(w, e.sources, e.errs)
+}
+
+// loadCRDs loads the CRDs of the handler. If some sources of the config file failed to load, the CRDs
+// of the others are returned together with the *PartialLoadError, so the command can render them and
+// report the failures at the end.
+func loadCRDs(h Handler) ([]*pkg.SchemaType, *PartialLoadError, error) {
+	crds, err := h.CRDs()
+
+	var partial *PartialLoadError
+	if errors.As(err, &partial) {
+		return crds, partial, nil
+	}
+
+	return crds, nil, err
+}
+
+// reportPartialLoad prints the summary of the failed sources, if any, and adds them to err, so the
+// command exits with an error.
+func reportPartialLoad(partial *PartialLoadError, err error) error {
+	if partial == nil {
+		return err
 	}
-	content, err := os.ReadFile(h.configFileLocation)
+
+	partial.Print(os.Stderr)
+
+	return errors.Join(err, partial)
+}
+
+// loadConfig reads and validates the config file. Fields that don't exist are rejected, so typos don't
+// silently result in missing CRDs.
+func loadConfig(location string) (*RenderConfig, error) {
+	if _, err := os.Stat(location); os.IsNotExist(err) {
+		return nil, fmt.Errorf("file under '%s' does not exist", location)
+	}
+
+	content, err := os.ReadFile(location)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	configFile := &RenderConfig{}
-	if err = yaml.Unmarshal(content, configFile); err != nil {
+	if err := strict.Unmarshal(content, configFile); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
 	}
 
-	// for each api group, call file handler and folder handler and gather all
-	// the CRDs.
-	var result []*pkg.SchemaType
+	var errs []error
+	for i := range configFile.APIGroups {
+		errs = append(errs, configFile.APIGroups[i].validate())
+	}
 
-	for _, group := range configFile.APIGroups {
-		for _, file := range group.Files {
-			handler := FileHandler{location: file, group: group.Name}
-			fileResults, err := handler.CRDs()
-			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for files in groups %s: %w", group.Name, err)
-			}
+	return configFile, errors.Join(errs...)
+}
 
-			result = append(result, fileResults...)
+// source is a location of an API group in the config file and the handler that loads its CRDs.
+type source struct {
+	group       *APIGroups
+	description string
+	handler     Handler
+}
+
+// sources returns the sources of all API groups in the order they are defined in.
+func (h *ConfigHandler) sources(configFile *RenderConfig) ([]source, error) {
+	var sources []source
+	for gi := range configFile.APIGroups {
+		group := &configFile.APIGroups[gi]
+		add := func(description string, handler Handler) {
+			sources = append(sources, source{group: group, description: description, handler: handler})
+		}
+
+		for _, file := range group.Files {
+			add("file "+file, &FileHandler{location: file, group: group.Name})
 		}
 
 		for _, folder := range group.Folders {
-			handler := FolderHandler{location: folder, group: group.Name}
-			folderResults, err := handler.CRDs()
-			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for folders %s: %w", handler.location, err)
-			}
+			add("folder "+folder, &FolderHandler{location: folder, group: group.Name})
+		}
 
-			result = append(result, folderResults...)
+		for _, chart := range group.Charts {
+			add("chart "+chart, &HelmHandler{location: chart, group: group.Name})
 		}
 
 		for _, url := range group.URLs {
-			handler := URLHandler{
+			opts, err := url.httpOptions()
+			if err != nil {
+				return nil, fmt.Errorf("invalid options for url %s: %w", url.URL, err)
+			}
+
+			add("url "+url.URL, &URLHandler{
 				url:      url.URL,
 				username: url.Username,
 				password: url.Password,
 				token:    url.Token,
 				group:    group.Name,
+				cache:    h.cache,
+				http:     opts,
+			})
+		}
+
+		for _, url := range group.GitURLs {
+			add("git url "+url.URL, &GitHandler{
+				URL:          url.URL,
+				Username:     url.Username,
+				Password:     url.Password,
+				Token:        url.Token,
+				Tag:          url.Tag,
+				Ref:          url.Ref,
+				Path:         url.Path,
+				Include:      url.Include,
+				Exclude:      url.Exclude,
+				IncludeTests: url.IncludeTests,
+				privSSHKey:   url.PrivateKey,
+				useSSHAgent:  url.UseSSHAgent,
+				group:        group.Name,
+				cache:        h.cache,
+			})
+		}
+
+		for _, url := range group.OCIURLs {
+			add("oci url "+url.URL, &OCIHandler{
+				reference: url.URL,
+				username:  url.Username,
+				password:  url.Password,
+				token:     url.Token,
+				plainHTTP: url.PlainHTTP,
+				group:     group.Name,
+			})
+		}
+	}
+
+	return sources, nil
+}
+
+// load loads the sources with a pool of workers. The results and errors are returned at the index of
+// their source, so the output doesn't depend on which source finished first. Unless errors are
+// tolerated, no new sources are started after one failed.
+func (h *ConfigHandler) load(sources []source) ([][]*pkg.SchemaType, []error) {
+	results := make([][]*pkg.SchemaType, len(sources))
+	errs := make([]error, len(sources))
+
+	concurrency := h.concurrency
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(concurrency)
+	for i, s := range sources {
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
 			}
-			crds, err := handler.CRDs()
+
+			crds, err := s.handler.CRDs()
 			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for url %s: %w", handler.url, err)
+				errs[i] = err
+				if h.continueOnError {
+					return nil
+				}
+
+				return err
 			}
 
-			result = append(result, crds...)
+			results[i] = crds
+
+			return nil
+		})
+	}
+
+	// the errors are collected per source instead.
+	_ = g.Wait()
+
+	return results, errs
+}
+
+// printFailures prints a summary of the sources that failed to load.
+func printFailures(w io.Writer, sources []source, errs []error) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.SetTitle("Failed sources")
+	t.AppendHeader(table.Row{"Group", "Source", "Error"})
+	for i, s := range sources {
+		if errs[i] != nil {
+			t.AppendRow(table.Row{s.group.Name, s.description, text.WrapText(errs[i].Error(), wrapLen)})
 		}
+	}
+	t.Render()
+}
 
-		for _, url := range group.GitURLs {
-			handler := GitHandler{
-				URL:         url.URL,
-				Username:    url.Username,
-				Password:    url.Password,
-				Token:       url.Token,
-				Tag:         url.Tag,
-				privSSHKey:  url.PrivateKey,
-				useSSHAgent: url.UseSSHAgent,
-				group:       group.Name,
+// httpOptions returns the options of the URL handler configured for the url.
+func (u URLs) httpOptions() (httpOpts, error) {
+	timeout := defaultTimeout
+	if u.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(u.Timeout); err != nil {
+			return httpOpts{}, fmt.Errorf("failed to parse timeout: %w", err)
+		}
+	}
+
+	retries := defaultRetries
+	if u.Retries != nil {
+		retries = *u.Retries
+	}
+
+	return httpOpts{
+		client: fetcher.ClientOptions{
+			CABundle:   u.CABundle,
+			ClientCert: u.ClientCert,
+			ClientKey:  u.ClientKey,
+			Proxy:      u.Proxy,
+			Timeout:    timeout,
+		},
+		headers: u.Headers,
+		retries: retries,
+	}, nil
+}
+
+// expandEnv replaces references to environment variables like ${GITHUB_TOKEN} in the credentials and
+// headers of the config, so secrets don't have to be stored in the file.
+func (c *RenderConfig) expandEnv() error {
+	for gi := range c.APIGroups {
+		group := &c.APIGroups[gi]
+
+		var fields []*string
+		for i := range group.URLs {
+			u := &group.URLs[i]
+			fields = append(fields, &u.Username, &u.Password, &u.Token)
+			for name, value := range u.Headers {
+				expanded, err := credentials.ExpandEnv(value)
+				if err != nil {
+					return fmt.Errorf("failed to expand header %s of url %s: %w", name, u.URL, err)
+				}
+
+				u.Headers[name] = expanded
 			}
-			crds, err := handler.CRDs()
+		}
+
+		for i := range group.GitURLs {
+			u := &group.GitURLs[i]
+			fields = append(fields, &u.Username, &u.Password, &u.Token, &u.PrivateKey)
+		}
+
+		for i := range group.OCIURLs {
+			u := &group.OCIURLs[i]
+			fields = append(fields, &u.Username, &u.Password, &u.Token)
+		}
+
+		for _, field := range fields {
+			expanded, err := credentials.ExpandEnv(*field)
 			if err != nil {
-				return nil, fmt.Errorf("failed to process CRDs for git url %s: %w", handler.URL, err)
+				return fmt.Errorf("failed to expand credentials of group %s: %w", group.Name, err)
 			}
 
-			result = append(result, crds...)
+			*field = expanded
 		}
 	}
 
-	return result, nil
+	return nil
 }
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHandlerContinueOnError(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "cty.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`apiGroups:
+  - name: krok
+    files:
+      - ../sample-crd/delivery.krok.app_krokcommands.yaml
+      - missing.yaml
+`), 0o600))
+
+	h := &ConfigHandler{configFileLocation: config, concurrency: 1, continueOnError: true}
+	crds, err := h.CRDs()
+
+	var partial *PartialLoadError
+	require.True(t, errors.As(err, &partial))
+	assert.EqualError(t, err, "failed to load 1 of 2 sources")
+	require.Len(t, crds, 1)
+	assert.Equal(t, "KrokCommand", crds[0].Kind)
+
+	h.continueOnError = false
+	_, err = h.CRDs()
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &partial))
+}
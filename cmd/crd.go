@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +16,7 @@ import (
 const (
 	FormatHTML = "html"
 	FormatYAML = "yaml"
+	FormatSite = "site"
 )
 
 // crdCmd is the command that generates CRD output.
@@ -25,12 +27,15 @@ var crdCmd = &cobra.Command{
 }
 
 type crdGenArgs struct {
-	comments   bool
-	minimal    bool
-	skipRandom bool
-	output     string
-	format     string
-	stdOut     bool
+	comments    bool
+	minimal     bool
+	skipRandom  bool
+	output      string
+	format      string
+	stdOut      bool
+	siteURL     string
+	templateDir string
+	template    string
 }
 
 var crdArgs = &crdGenArgs{}
@@ -46,22 +51,33 @@ func init() {
 	f.BoolVarP(&crdArgs.minimal, "minimal", "l", false, "If set, only the minimal required example yaml is generated.")
 	f.BoolVar(&crdArgs.skipRandom, "no-random", false, "Skip generating random values that satisfy the property patterns.")
 	f.StringVarP(&crdArgs.output, "output", "o", "", "The location of the output file. Default is next to the CRD.")
-	f.StringVarP(&crdArgs.format, "format", "f", FormatYAML, "The format in which to output. Default is YAML. Options are: yaml, html.")
+	f.StringVarP(&crdArgs.format, "format", "f", FormatYAML, "The format in which to output. Default is YAML. Options are: yaml, html, site.")
 	f.BoolVarP(&crdArgs.stdOut, "stdout", "s", false, "If set, it will output the generated content to stdout.")
+	f.StringVar(&crdArgs.templateDir, "template-dir", "", "A folder of templates, partials and assets that replace the built-in ones of the same name or add new ones.")
+	f.StringVar(&crdArgs.template, "template", pkg.DefaultTemplate, "The template the HTML format is rendered with.")
+	f.StringVar(&crdArgs.siteURL, "site-url", "", "The URL the site is published under, like https://org.github.io/repo. If set, a sitemap is written for format site.")
 }
 
-func runGenerate(_ *cobra.Command, _ []string) error {
+func runGenerate(_ *cobra.Command, _ []string) (err error) {
 	crdHandler, err := constructHandler(args)
 	if err != nil {
 		return err
 	}
 
+	if crdArgs.format == FormatSite {
+		if crdArgs.output == "" || crdArgs.stdOut {
+			return errors.New("output must be set to a folder if format is site")
+		}
+	}
+
 	if crdArgs.format == FormatHTML {
 		if crdArgs.output == "" {
 			return errors.New("output must be set to a filename if format is HTML")
 		}
+	}
 
-		if err := pkg.LoadTemplates(); err != nil {
+	if crdArgs.format == FormatHTML || crdArgs.format == FormatSite {
+		if err := loadTemplates(); err != nil {
 			return fmt.Errorf("failed to load templates: %w", err)
 		}
 	}
@@ -76,37 +92,53 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 		crdArgs.output = filepath.Dir(loc)
 	}
 
-	crds, err := crdHandler.CRDs()
+	crds, partial, err := loadCRDs(crdHandler)
 	if err != nil {
 		return fmt.Errorf("failed to load CRDs: %w", err)
 	}
 
-	var w io.WriteCloser
-	if crdArgs.format == FormatHTML {
-		if crdArgs.stdOut {
-			w = os.Stdout
-		} else {
-			w, err = os.Create(crdArgs.output)
-			if err != nil {
-				return fmt.Errorf("failed to create output file: %w", err)
-			}
-		}
+	defer func() {
+		err = reportPartialLoad(partial, err)
+	}()
 
-		opts := pkg.RenderOpts{
-			Comments: crdArgs.comments,
-			Minimal:  crdArgs.minimal,
-			Random:   crdArgs.skipRandom,
-		}
+	opts := pkg.RenderOpts{
+		Comments: crdArgs.comments,
+		Minimal:  crdArgs.minimal,
+		Random:   crdArgs.skipRandom,
+		Template: crdArgs.template,
+	}
 
-		return pkg.RenderContent(w, crds, opts)
+	switch crdArgs.format {
+	case FormatHTML:
+		return renderHTML(crds, opts)
+	case FormatSite:
+		return renderSite(crds, opts)
 	}
 
 	var errs []error //nolint:prealloc // nope
-	for _, crd := range crds {
+	for i, crd := range crds {
+		var w io.WriteCloser
 		if crdArgs.stdOut {
-			w = os.Stdout
+			// Generate closes the writer after every CRD.
+			w = nopWriteCloser{Writer: os.Stdout}
+
+			if i > 0 {
+				if _, err := w.Write([]byte("\n---\n")); err != nil {
+					return fmt.Errorf("failed to write yaml delimiter: %w", err)
+				}
+			}
 		} else {
-			outputLocation := filepath.Join(crdArgs.output, crd.Kind+"_sample."+crdArgs.format)
+			output := crdArgs.output
+			if crd.Rendering.Output != "" {
+				output = crd.Rendering.Output
+				if err := os.MkdirAll(output, 0o755); err != nil { //nolint:mnd // default directory permissions
+					errs = append(errs, fmt.Errorf("failed to create output folder: '%s': %w", output, err))
+
+					continue
+				}
+			}
+
+			outputLocation := filepath.Join(output, crd.Kind+"_sample."+crdArgs.format)
 			// closed later during render
 			outputFile, err := os.Create(outputLocation)
 			if err != nil {
@@ -118,13 +150,101 @@ func runGenerate(_ *cobra.Command, _ []string) error {
 			w = outputFile
 		}
 
-		errs = append(errs, pkg.Generate(crd, w, crdArgs.comments, crdArgs.minimal, crdArgs.skipRandom))
+		crdOpts := crd.Rendering.Options(opts)
+		errs = append(errs, pkg.Generate(crd, w, crdOpts.Comments, crdOpts.Minimal, crdOpts.Random))
 	}
 
 	return errors.Join(errs...)
 }
 
+// loadTemplates loads the built-in templates and the ones of the template folder.
+func loadTemplates() error {
+	if crdArgs.templateDir == "" {
+		return pkg.LoadTemplates()
+	}
+
+	info, err := os.Stat(crdArgs.templateDir)
+	if err != nil {
+		return fmt.Errorf("failed to open template folder: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("template folder %s is not a folder", crdArgs.templateDir)
+	}
+
+	return pkg.LoadTemplates(os.DirFS(crdArgs.templateDir))
+}
+
+// renderHTML renders the CRDs into the output file. CRDs of groups with their own output are rendered
+// into separate files.
+func renderHTML(crds []*pkg.SchemaType, opts pkg.RenderOpts) error {
+	if crdArgs.stdOut {
+		return pkg.RenderContent(os.Stdout, crds, opts)
+	}
+
+	outputs, byOutput := groupByOutput(crds)
+	for _, output := range outputs {
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil { //nolint:mnd // default directory permissions
+			return fmt.Errorf("failed to create output folder: '%s': %w", filepath.Dir(output), err)
+		}
+
+		w, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+
+		if err := pkg.RenderContent(w, byOutput[output], opts); err != nil {
+			return fmt.Errorf("failed to render %s: %w", output, err)
+		}
+	}
+
+	return nil
+}
+
+// renderSite renders the CRDs into a site in the output folder. CRDs of groups with their own output are
+// rendered into separate sites.
+func renderSite(crds []*pkg.SchemaType, opts pkg.RenderOpts) error {
+	outputs, byOutput := groupByOutput(crds)
+	for _, output := range outputs {
+		if err := pkg.RenderSite(output, byOutput[output], opts, crdArgs.siteURL); err != nil {
+			return fmt.Errorf("failed to render site %s: %w", output, err)
+		}
+	}
+
+	return nil
+}
+
+// groupByOutput groups the CRDs by the location they are rendered to. The locations are returned in the
+// order they are first seen in.
+func groupByOutput(crds []*pkg.SchemaType) ([]string, map[string][]*pkg.SchemaType) {
+	var outputs []string
+	byOutput := map[string][]*pkg.SchemaType{}
+	for _, crd := range crds {
+		output := cmp.Or(crd.Rendering.Output, crdArgs.output)
+		if _, ok := byOutput[output]; !ok {
+			outputs = append(outputs, output)
+		}
+
+		byOutput[output] = append(byOutput[output], crd)
+	}
+
+	return outputs, byOutput
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
 func constructHandler(args *rootArgs) (Handler, error) {
+	c, err := newCache(args)
+	if err != nil {
+		return nil, err
+	}
+
 	var crdHandler Handler
 
 	switch {
@@ -132,30 +252,52 @@ func constructHandler(args *rootArgs) (Handler, error) {
 		crdHandler = &FileHandler{location: args.fileLocation}
 	case args.folderLocation != "":
 		crdHandler = &FolderHandler{location: args.folderLocation}
+	case args.chartLocation != "":
+		crdHandler = &HelmHandler{location: args.chartLocation}
+	case args.openAPILocation != "":
+		crdHandler = &OpenAPIHandler{location: args.openAPILocation}
 	case args.configFileLocation != "":
-		crdHandler = &ConfigHandler{configFileLocation: args.configFileLocation}
+		crdHandler = &ConfigHandler{
+			configFileLocation: args.configFileLocation,
+			cache:              c,
+			concurrency:        args.concurrency,
+			continueOnError:    args.continueOnError,
+		}
 	case args.gitURL != "":
-		crdHandler = &GitHandler{
-			URL:         args.gitURL,
-			Username:    args.username,
-			Password:    args.password,
-			Token:       args.token,
-			Tag:         args.tag,
-			caBundle:    args.caBundle,
-			privSSHKey:  args.privSSHKey,
-			useSSHAgent: args.useSSHAgent,
+		crdHandler = newGitHandler(args, c)
+	case args.ociURL != "":
+		crdHandler = &OCIHandler{
+			reference: args.ociURL,
+			username:  args.username,
+			password:  args.password,
+			token:     args.token,
+			plainHTTP: args.plainHTTP,
+		}
+	case args.kubeconfig != "" || args.kubeContext != "":
+		crdHandler = &ClusterHandler{
+			kubeconfig:    args.kubeconfig,
+			context:       args.kubeContext,
+			groups:        args.apiGroups,
+			labelSelector: args.labelSelector,
 		}
 	case args.url != "":
+		opts, err := httpOptions(args)
+		if err != nil {
+			return nil, err
+		}
+
 		crdHandler = &URLHandler{
 			url:      args.url,
 			username: args.username,
 			password: args.password,
 			token:    args.token,
+			cache:    c,
+			http:     opts,
 		}
 	}
 
 	if crdHandler == nil {
-		return nil, errors.New("one of the flags (file, folder, url, configFile) must be set")
+		return nil, errors.New("one of the flags (file, folder, chart, openapi, url, git-url, oci, kubeconfig, context, configFile) must be set")
 	}
 
 	return crdHandler, nil
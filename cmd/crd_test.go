@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestRenderHTMLCreatesOutputFolder(t *testing.T) {
+	require.NoError(t, pkg.LoadTemplates())
+
+	crds, err := (&FileHandler{location: "../sample-crd/delivery.krok.app_krokcommands.yaml"}).CRDs()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	crds[0].Rendering.Output = filepath.Join(dir, "docs", "internal", "index.html")
+
+	defaultOutput := crdArgs.output
+	t.Cleanup(func() {
+		crdArgs.output = defaultOutput
+	})
+	crdArgs.output = filepath.Join(dir, "index.html")
+
+	require.NoError(t, renderHTML(crds, pkg.RenderOpts{Template: pkg.DefaultTemplate}))
+	assert.FileExists(t, crds[0].Rendering.Output)
+}
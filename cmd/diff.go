@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/diff"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/oci"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+var (
+	// diffCmd compares two sets of CRDs and reports breaking changes.
+	diffCmd = &cobra.Command{
+		Use:   "diff old new",
+		Short: "Compare two CRDs and report breaking changes between them.",
+		Long: `Compare two CRDs and report breaking changes between them.
+
+Both old and new can be a file, a folder, a Helm chart, a config file, a URL, a git repository or
+an OCI artifact. Git repositories are recognised by a git@ prefix or a .git suffix, Helm charts by
+a Chart.yaml or a .tgz suffix and OCI artifacts by an oci:// prefix. Alternatively, two refs of the same git repository can be compared using
+--git-url together with --from and --to.
+The command exits with a non-zero exit code if any breaking change is found.`,
+		Args:         validateDiffArgs,
+		SilenceUsage: true,
+		RunE:         runDiff,
+	}
+
+	diffArgs = &diffCmdArgs{}
+)
+
+type diffCmdArgs struct {
+	source rootArgs
+	output string
+	from   string
+	to     string
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	f := diffCmd.PersistentFlags()
+	f.StringVarP(&diffArgs.output, "output", "o", OutputText, "The format of the report. Options are: text, json.")
+	f.StringVarP(&diffArgs.source.gitURL, "git-url", "g", "", "If provided, the CRDs of two refs of this git repository are compared.")
+	f.StringVar(&diffArgs.from, "from", "", "The old tag, branch or commit of the git repository.")
+	f.StringVar(&diffArgs.to, "to", "", "The new tag, branch or commit of the git repository.")
+	addSourceOptionFlags(f, &diffArgs.source)
+}
+
+func validateDiffArgs(cmd *cobra.Command, positional []string) error {
+	if diffArgs.source.gitURL == "" {
+		if diffArgs.from != "" || diffArgs.to != "" {
+			return errors.New("--from and --to can only be used together with --git-url")
+		}
+
+		return cobra.ExactArgs(2)(cmd, positional) //nolint:mnd // old and new
+	}
+
+	if len(positional) > 0 {
+		return errors.New("no arguments are accepted when comparing refs of a git repository")
+	}
+
+	if diffArgs.from == "" || diffArgs.to == "" {
+		return errors.New("both --from and --to must be set when comparing refs of a git repository")
+	}
+
+	if diffArgs.source.tag != "" || diffArgs.source.ref != "" {
+		return errors.New("--tag and --ref can't be used together with --from and --to")
+	}
+
+	return nil
+}
+
+func runDiff(_ *cobra.Command, positional []string) error {
+	if diffArgs.source.gitURL != "" {
+		return runGitDiff()
+	}
+
+	oldHandler, err := handlerForLocation(positional[0], &diffArgs.source)
+	if err != nil {
+		return err
+	}
+
+	newHandler, err := handlerForLocation(positional[1], &diffArgs.source)
+	if err != nil {
+		return err
+	}
+
+	oldCRDs, oldPartial, err := loadCRDs(oldHandler)
+	if err != nil {
+		return fmt.Errorf("failed to load old CRDs: %w", err)
+	}
+
+	newCRDs, newPartial, err := loadCRDs(newHandler)
+	if err != nil {
+		return fmt.Errorf("failed to load new CRDs: %w", err)
+	}
+
+	err = displayReport(os.Stdout, diff.Compare(oldCRDs, newCRDs))
+
+	return reportPartialLoad(newPartial, reportPartialLoad(oldPartial, err))
+}
+
+func runGitDiff() error {
+	c, err := newCache(&diffArgs.source)
+	if err != nil {
+		return err
+	}
+
+	oldCRDs, newCRDs, err := newGitHandler(&diffArgs.source, c).CRDsForRefs(diffArgs.from, diffArgs.to)
+	if err != nil {
+		return fmt.Errorf("failed to load CRDs: %w", err)
+	}
+
+	return displayReport(os.Stdout, diff.Compare(oldCRDs, newCRDs))
+}
+
+// handlerForLocation constructs a handler based on the shape of the location.
+func handlerForLocation(location string, source *rootArgs) (Handler, error) {
+	a := *source
+
+	switch {
+	case strings.HasPrefix(location, "git@") || strings.HasSuffix(location, ".git"):
+		a.gitURL = location
+	case strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://"):
+		a.url = location
+	case strings.HasPrefix(location, oci.Scheme):
+		a.ociURL = location
+	case location == stdinLocation:
+		a.fileLocation = location
+	default:
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find location %s: %w", location, err)
+		}
+
+		switch {
+		case isChart(location):
+			a.chartLocation = location
+		case info.IsDir():
+			a.folderLocation = location
+		case isOpenAPIDocument(location):
+			a.openAPILocation = location
+		case isConfigFile(location):
+			a.configFileLocation = location
+		default:
+			a.fileLocation = location
+		}
+	}
+
+	return constructHandler(&a)
+}
+
+// isConfigFile returns true if the file is a configuration file that defines API groups.
+func isConfigFile(location string) bool {
+	content, err := os.ReadFile(location)
+	if err != nil {
+		return false
+	}
+
+	config := &RenderConfig{}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return false
+	}
+
+	return len(config.APIGroups) > 0
+}
+
+func displayReport(w io.Writer, report *diff.Report) error {
+	switch diffArgs.output {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+	case OutputText:
+		displayTextReport(w, report)
+	default:
+		return fmt.Errorf("unknown output format %s", diffArgs.output)
+	}
+
+	if breaking := report.Breaking(); breaking > 0 {
+		return fmt.Errorf("%d breaking change(s) found", breaking)
+	}
+
+	return nil
+}
+
+func displayTextReport(w io.Writer, report *diff.Report) {
+	if len(report.Changes) == 0 {
+		_, _ = fmt.Fprintln(w, "No changes found.")
+
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Severity", "Kind", "Version", "Path", "Change"})
+	rows := make([]table.Row, 0, len(report.Changes))
+	for _, c := range report.Changes {
+		severity := color.GreenString("non-breaking")
+		if c.Breaking {
+			severity = color.RedString("breaking")
+		}
+
+		rows = append(rows, table.Row{
+			severity, c.Group + "/" + c.Kind, c.Version, c.Path, text.WrapText(c.Message, wrapLen),
+		})
+	}
+	t.AppendRows(rows)
+	t.Render()
+
+	breaking := report.Breaking()
+	_, _ = fmt.Fprintf(w, "\nChanges total: %d, breaking: %d, non-breaking: %d\n", len(report.Changes), breaking, len(report.Changes)-breaking)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDiffArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       diffCmdArgs
+		positional []string
+		wantErr    string
+	}{
+		{name: "old and new", positional: []string{"old.yaml", "new.yaml"}},
+		{name: "missing new", positional: []string{"old.yaml"}, wantErr: "accepts 2 arg(s), received 1"},
+		{name: "from without git url", args: diffCmdArgs{from: "v1"}, positional: []string{"old.yaml", "new.yaml"}, wantErr: "--from and --to can only be used together with --git-url"},
+		{name: "to without git url", args: diffCmdArgs{to: "v2"}, positional: []string{"old.yaml", "new.yaml"}, wantErr: "--from and --to can only be used together with --git-url"},
+		{name: "git refs", args: diffCmdArgs{source: rootArgs{gitURL: "https://example.com/crds.git"}, from: "v1", to: "v2"}},
+		{name: "git refs with arguments", args: diffCmdArgs{source: rootArgs{gitURL: "https://example.com/crds.git"}, from: "v1", to: "v2"}, positional: []string{"old.yaml"}, wantErr: "no arguments are accepted"},
+		{name: "git url without to", args: diffCmdArgs{source: rootArgs{gitURL: "https://example.com/crds.git"}, from: "v1"}, wantErr: "both --from and --to must be set"},
+		{name: "git refs with a tag", args: diffCmdArgs{source: rootArgs{gitURL: "https://example.com/crds.git", tag: "v3"}, from: "v1", to: "v2"}, wantErr: "--tag and --ref can't be used together with --from and --to"},
+	}
+
+	defaultArgs := *diffArgs
+	t.Cleanup(func() {
+		*diffArgs = defaultArgs
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*diffArgs = tt.args
+
+			err := validateDiffArgs(diffCmd, tt.positional)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
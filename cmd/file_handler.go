@@ -2,21 +2,31 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
-
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
 	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
 )
 
+// stdinLocation is the file location that reads the CRDs from stdin.
+const stdinLocation = "-"
+
 type FileHandler struct {
 	location string
 	group    string
 }
 
 func (h *FileHandler) CRDs() ([]*pkg.SchemaType, error) {
+	if h.location == stdinLocation {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+
+		return extractSchemaTypes(content, "stdin", h.group)
+	}
+
 	if _, err := os.Stat(h.location); os.IsNotExist(err) {
 		return nil, fmt.Errorf("file under '%s' does not exist", h.location)
 	}
@@ -25,28 +35,34 @@ func (h *FileHandler) CRDs() ([]*pkg.SchemaType, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	content, err = sanitize.Sanitize(content)
+	return extractSchemaTypes(content, h.location, h.group)
+}
+
+// extractSchemaTypes returns every CRD in the content. The content may contain multiple
+// documents and lists of CRDs.
+func extractSchemaTypes(content []byte, location, group string) ([]*pkg.SchemaType, error) {
+	content, err := sanitize.Sanitize(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sanitize content: %w", err)
 	}
 
-	crd := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(content, crd); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal into custom resource definition: %w", err)
-	}
+	return extractRenderedSchemaTypes(content, location, group)
+}
 
-	schemaType, err := pkg.ExtractSchemaType(crd)
+// extractRenderedSchemaTypes returns every CRD in content that doesn't contain templates.
+func extractRenderedSchemaTypes(content []byte, location, group string) ([]*pkg.SchemaType, error) {
+	schemaTypes, err := pkg.ExtractSchemaTypes(content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract schema type: %w", err)
+		return nil, fmt.Errorf("failed to extract schema types: %w", err)
 	}
 
-	if schemaType == nil {
-		return nil, nil
-	}
+	for _, schemaType := range schemaTypes {
+		schemaType.Location = location
 
-	if h.group != "" {
-		schemaType.Rendering = pkg.Rendering{Group: h.group}
+		if group != "" {
+			schemaType.Rendering = pkg.Rendering{Group: group}
+		}
 	}
 
-	return []*pkg.SchemaType{schemaType}, nil
+	return schemaTypes, nil
 }
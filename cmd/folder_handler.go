@@ -6,11 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
-
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
 )
 
 type FolderHandler struct {
@@ -34,41 +30,25 @@ func (h *FolderHandler) CRDs() ([]*pkg.SchemaType, error) {
 			return nil
 		}
 
-		if filepath.Ext(path) != ".yaml" {
-			_, _ = fmt.Fprintln(os.Stderr, "skipping file "+path)
-
-			return nil
-		}
-
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return fmt.Errorf("failed to read file: %w", err)
 		}
 
-		content, err = sanitize.Sanitize(content)
-		if err != nil {
-			return fmt.Errorf("failed to sanitize content: %w", err)
+		// folders of charts and kustomizations are full of other files, so they are skipped silently.
+		if !pkg.ContainsCRD(content) {
+			return nil
 		}
 
-		crd := &unstructured.Unstructured{}
-		if err := yaml.Unmarshal(content, crd); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, "skipping none CRD file: "+path)
-
-			return nil //nolint:nilerr // intentional
-		}
-		schemaType, err := pkg.ExtractSchemaType(crd)
+		schemaTypes, err := extractSchemaTypes(content, path, h.group)
 		if err != nil {
-			return fmt.Errorf("failed to extract schema type: %w", err)
-		}
+			_, _ = fmt.Fprintf(os.Stderr, "skipping file %s that looks like a CRD but failed to parse: %s\n", path, err)
 
-		if schemaType != nil {
-			if h.group != "" {
-				schemaType.Rendering = pkg.Rendering{Group: h.group}
-			}
-
-			crds = append(crds, schemaType)
+			return nil
 		}
 
+		crds = append(crds, schemaTypes...)
+
 		return nil
 	}); err != nil {
 		return nil, fmt.Errorf("failed to walk the selected folder: %w", err)
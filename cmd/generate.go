@@ -1,12 +1,32 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultRetries = 3
+	// defaultConcurrency is the number of sources of a config file that are loaded at the same time.
+	defaultConcurrency = 4
+	// retryBackoff is the wait before the first retry of a failed request.
+	retryBackoff = time.Second
 )
 
 type rootArgs struct {
 	fileLocation       string
 	folderLocation     string
+	chartLocation      string
+	openAPILocation    string
 	configFileLocation string
 	url                string
 	username           string
@@ -14,9 +34,30 @@ type rootArgs struct {
 	token              string
 	tag                string
 	caBundle           string
+	clientCert         string
+	clientKey          string
+	proxy              string
+	headers            []string
+	timeout            time.Duration
+	retries            int
 	privSSHKey         string
 	useSSHAgent        bool
 	gitURL             string
+	ociURL             string
+	plainHTTP          bool
+	kubeconfig         string
+	kubeContext        string
+	apiGroups          []string
+	labelSelector      string
+	ref                string
+	gitPath            string
+	include            []string
+	exclude            []string
+	includeTests       bool
+	cacheDir           string
+	offline            bool
+	concurrency        int
+	continueOnError    bool
 }
 
 var (
@@ -32,17 +73,115 @@ var (
 func init() {
 	rootCmd.AddCommand(generateCmd)
 	// using persistent flags so all flags will be available for all sub commands.
-	f := generateCmd.PersistentFlags()
-	f.StringVarP(&args.fileLocation, "crd", "c", "", "The CRD file to generate a yaml from.")
-	f.StringVarP(&args.folderLocation, "folder", "r", "", "A folder from which to parse a series of CRDs.")
-	f.StringVarP(&args.url, "url", "u", "", "If provided, will use this URL to fetch CRD YAML content from.")
-	f.StringVarP(&args.gitURL, "git-url", "g", "", "If provided, CRDs will be discovered using a git repository.")
-	f.StringVar(&args.username, "username", "", "Optional username to authenticate a URL.")
-	f.StringVar(&args.password, "password", "", "Optional password to authenticate a URL.")
-	f.StringVar(&args.token, "token", "", "A bearer token to authenticate a URL.")
-	f.StringVar(&args.configFileLocation, "config", "", "An optional configuration file that can define grouping data for various rendered crds.")
-	f.StringVar(&args.tag, "tag", "", "The ref to check out. Default is head.")
-	f.StringVar(&args.caBundle, "ca-bundle-file", "", "Additional certificate bundle to load. Should the name of the file.")
-	f.StringVar(&args.privSSHKey, "private-ssh-key-file", "", "Private key to use for cloning. Should the name of the file.")
-	f.BoolVar(&args.useSSHAgent, "ssh-agent", false, "If set, the configured SSH agent will be used to clone the repository..")
+	addSourceFlags(generateCmd.PersistentFlags(), args)
+}
+
+// addSourceFlags adds the flags that select where CRDs are loaded from.
+func addSourceFlags(f *pflag.FlagSet, a *rootArgs) {
+	f.StringVarP(&a.fileLocation, "crd", "c", "", "The CRD file to generate a yaml from. Use - to read it from stdin.")
+	f.StringVarP(&a.folderLocation, "folder", "r", "", "A folder from which to parse a series of CRDs.")
+	f.StringVar(&a.chartLocation, "chart", "", "A Helm chart folder or packaged .tgz chart from which to parse CRDs.")
+	f.StringVar(&a.openAPILocation, "openapi", "", "An OpenAPI v3 document served by the apiserver at /openapi/v3, or a folder of them, from which to parse built-in and aggregated kinds.")
+	f.StringVarP(&a.url, "url", "u", "", "If provided, will use this URL to fetch CRD YAML content from.")
+	f.StringVarP(&a.gitURL, "git-url", "g", "", "If provided, CRDs will be discovered using a git repository.")
+	f.StringVar(&a.ociURL, "oci", "", "If provided, CRDs will be discovered in a Helm chart or CRD bundle stored as an OCI artifact, like oci://registry/repository:tag.")
+	f.StringVar(&a.kubeconfig, "kubeconfig", "", "If provided, the CRDs installed in the cluster of this kubeconfig file will be discovered.")
+	f.StringVar(&a.kubeContext, "context", "", "If provided, the CRDs installed in the cluster of this kubeconfig context will be discovered.")
+	f.StringVar(&a.configFileLocation, "config", "", "An optional configuration file that can define grouping data for various rendered crds.")
+	addSourceOptionFlags(f, a)
+}
+
+// addSourceOptionFlags adds the flags that configure how CRDs are loaded from a source, like credentials and
+// git refs. Commands that take the location of their sources as arguments only add these.
+func addSourceOptionFlags(f *pflag.FlagSet, a *rootArgs) {
+	f.BoolVar(&a.plainHTTP, "plain-http", false, "Use HTTP instead of HTTPS to connect to the OCI registry.")
+	f.StringSliceVar(&a.apiGroups, "api-group", nil, "Only discover CRDs of these API groups in the cluster.")
+	f.StringVar(&a.labelSelector, "selector", "", "Only discover CRDs in the cluster matching this label selector.")
+	f.StringVar(&a.username, "username", "", "Optional username to authenticate a URL.")
+	f.StringVar(&a.password, "password", "", "Optional password to authenticate a URL.")
+	f.StringVar(&a.token, "token", "", "A bearer token to authenticate a URL.")
+	f.IntVar(&a.concurrency, "concurrency", defaultConcurrency, "The number of sources of the configuration file that are loaded at the same time.")
+	f.BoolVar(&a.continueOnError, "continue-on-error", false, "Use the sources of the configuration file that could be loaded and print a summary of the ones that failed instead of stopping at the first failure.")
+	f.StringVar(&a.tag, "tag", "", "The ref to check out. Default is head.")
+	f.StringVar(&a.ref, "ref", "", "A branch or commit SHA of the git repository to check out. Commit SHAs need the whole history of the repository, which is loaded into memory if caching is disabled with an empty --cache-dir.")
+	f.StringVar(&a.gitPath, "path", "", "Only discover CRDs in this directory of the git repository.")
+	f.StringSliceVar(&a.include, "include", nil, "Only discover CRDs in files of the git repository matching these glob patterns.")
+	f.StringSliceVar(&a.exclude, "exclude", nil, "Skip files of the git repository matching these glob patterns.")
+	f.BoolVar(&a.includeTests, "include-tests", false, "Discover CRDs in test directories of the git repository, which are skipped by default.")
+	f.StringVar(&a.privSSHKey, "private-ssh-key-file", "", "Private key to use for cloning. Should the name of the file.")
+	f.BoolVar(&a.useSSHAgent, "ssh-agent", false, "If set, the configured SSH agent will be used to clone the repository..")
+	addHTTPFlags(f, a)
+	addCacheFlags(f, a)
+}
+
+// addHTTPFlags adds the flags that configure how URLs are fetched.
+func addHTTPFlags(f *pflag.FlagSet, a *rootArgs) {
+	f.StringVar(&a.caBundle, "ca-bundle-file", "", "Additional certificate bundle to trust when fetching URLs and cloning git repositories. Should be the name of the file.")
+	f.StringVar(&a.clientCert, "client-cert-file", "", "Client certificate to authenticate a URL with mutual TLS. Should be the name of the file.")
+	f.StringVar(&a.clientKey, "client-key-file", "", "Key of the client certificate. Should be the name of the file.")
+	f.StringVar(&a.proxy, "proxy", "", "The proxy to fetch URLs through. Defaults to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.")
+	f.StringArrayVarP(&a.headers, "header", "H", nil, "A header to add to the requests of a URL, like 'X-Api-Key: value'. Can be repeated.")
+	f.DurationVar(&a.timeout, "timeout", defaultTimeout, "The timeout of a request to a URL.")
+	f.IntVar(&a.retries, "retries", defaultRetries, "How many times a request to a URL is retried if it fails with a 5xx or 429 status code.")
+}
+
+// httpOptions returns the options of the URL handler configured by the flags.
+func httpOptions(a *rootArgs) (httpOpts, error) {
+	headers, err := parseHeaders(a.headers)
+	if err != nil {
+		return httpOpts{}, err
+	}
+
+	return httpOpts{
+		client: fetcher.ClientOptions{
+			CABundle:   a.caBundle,
+			ClientCert: a.clientCert,
+			ClientKey:  a.clientKey,
+			Proxy:      a.proxy,
+			Timeout:    a.timeout,
+		},
+		headers: headers,
+		retries: a.retries,
+	}, nil
+}
+
+// parseHeaders parses headers in the form 'Name: value'.
+func parseHeaders(headers []string) (map[string]string, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(headers))
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("header %q must be in the form 'Name: value'", header)
+		}
+
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return result, nil
+}
+
+// addCacheFlags adds the flags that configure caching of git repositories and URL content.
+func addCacheFlags(f *pflag.FlagSet, a *rootArgs) {
+	// caching is disabled if the user's cache directory can't be determined.
+	dir, _ := cache.DefaultDir()
+
+	f.StringVar(&a.cacheDir, "cache-dir", dir, "The directory in which git repositories and URL content are cached. Set it to an empty string to disable caching.")
+	f.BoolVar(&a.offline, "offline", false, "Only use the cached git repositories and URL content instead of fetching them.")
+}
+
+// newCache returns the cache configured by the flags or nil if caching is disabled.
+func newCache(a *rootArgs) (*cache.Cache, error) {
+	if a.cacheDir == "" {
+		if a.offline {
+			return nil, errors.New("--offline requires a --cache-dir")
+		}
+
+		return nil, nil
+	}
+
+	return cache.New(a.cacheDir, a.offline), nil
 }
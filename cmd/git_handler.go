@@ -1,22 +1,26 @@
 package cmd
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/storage/memory"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/glob"
 )
 
 type GitHandler struct {
@@ -25,22 +29,105 @@ type GitHandler struct {
 	Password string
 	Token    string
 	Tag      string
+	// Ref is a branch or a commit SHA to check out instead of a tag or HEAD.
+	Ref string
+	// Path limits the discovery to a directory of the repository.
+	Path string
+	// Include and Exclude are glob patterns of files to consider. Patterns without a / match the file name,
+	// others the whole path. A ** segment matches any number of directories.
+	Include []string
+	Exclude []string
+	// IncludeTests includes files in directories called test, which are skipped by default.
+	IncludeTests bool
 
 	caBundle    string
 	privSSHKey  string
 	useSSHAgent bool
 	group       string // this is used by the configfile.
+	// cache keeps a clone of the repository that is fetched incrementally. If nil, the repository
+	// is cloned into memory.
+	cache *cache.Cache
+
+	include []glob.Pattern
+	exclude []glob.Pattern
+}
+
+// newGitHandler returns the handler of the git repository configured by the flags.
+func newGitHandler(args *rootArgs, c *cache.Cache) *GitHandler {
+	return &GitHandler{
+		URL:          args.gitURL,
+		Username:     args.username,
+		Password:     args.password,
+		Token:        args.token,
+		Tag:          args.tag,
+		Ref:          args.ref,
+		Path:         args.gitPath,
+		Include:      args.include,
+		Exclude:      args.exclude,
+		IncludeTests: args.includeTests,
+		caBundle:     args.caBundle,
+		privSSHKey:   args.privSSHKey,
+		useSSHAgent:  args.useSSHAgent,
+		cache:        c,
+	}
 }
 
-func (g *GitHandler) CRDs() ([]*pkg.SchemaType, error) {
+func (g *GitHandler) CRDs() (_ []*pkg.SchemaType, err error) {
+	secrets := []string{g.Password, g.Token}
+	defer func() {
+		err = credentials.Redact(err, secrets...)
+	}()
+
+	if err := g.compilePatterns(); err != nil {
+		return nil, err
+	}
+
 	opts, err := g.constructGitOptions()
 	if err != nil {
 		return nil, err
 	}
 
+	secrets = append(secrets, authSecrets(opts.Auth)...)
+
+	if g.cache != nil {
+		defer g.cache.LockGit(g.URL)()
+	}
+
+	var (
+		r    *git.Repository
+		hash *plumbing.Hash
+	)
+	if err := g.withStoredCredentials(opts, func() (err error) {
+		r, hash, err = g.checkout(opts)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	crds, err := g.gatherSchemaTypesForHash(r, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, "Discovered number of CRDs: ", len(crds))
+
+	return crds, nil
+}
+
+// checkout clones the repository and resolves the configured ref, tag or HEAD.
+func (g *GitHandler) checkout(opts *git.CloneOptions) (*git.Repository, *plumbing.Hash, error) {
+	if g.cache != nil {
+		return g.checkoutCached(opts)
+	}
+
+	if g.Ref != "" {
+		return g.checkoutRef(opts)
+	}
+
 	r, err := git.Clone(memory.NewStorage(), nil, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error cloning git repository: %w", err)
+		return nil, nil, fmt.Errorf("error cloning git repository: %w", err)
 	}
 
 	var ref *plumbing.Reference
@@ -50,26 +137,284 @@ func (g *GitHandler) CRDs() ([]*pkg.SchemaType, error) {
 		ref, err = r.Head()
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct reference: %w", err)
+		return nil, nil, fmt.Errorf("failed to construct reference: %w", err)
 	}
 
-	crds, err := g.gatherSchemaTypesForRef(r, ref)
+	// Need to resolve the ref first to the right hash otherwise it's not found.
+	hash, err := r.ResolveRevision(plumbing.Revision(ref.Hash().String()))
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("failed to resolve revision: %w", err)
 	}
 
-	_, _ = fmt.Fprintln(os.Stderr, "Discovered number of CRDs: ", len(crds))
+	return r, hash, nil
+}
 
-	return crds, nil
+// checkoutRef clones a branch with depth 1. If the ref isn't a branch, the whole history of the
+// repository is cloned into memory so commit SHAs can be resolved. The cached clone on disk is
+// used instead, unless caching is disabled.
+func (g *GitHandler) checkoutRef(opts *git.CloneOptions) (*git.Repository, *plumbing.Hash, error) {
+	branchOpts := *opts
+	branchOpts.ReferenceName = plumbing.NewBranchReferenceName(g.Ref)
+	branchOpts.SingleBranch = true
+
+	r, err := git.Clone(memory.NewStorage(), nil, &branchOpts)
+	if err == nil {
+		head, err := r.Head()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to construct reference: %w", err)
+		}
+
+		hash := head.Hash()
+
+		return r, &hash, nil
+	}
+
+	if !isRefNotFound(err) {
+		return nil, nil, fmt.Errorf("error cloning git repository: %w", err)
+	}
+
+	fullOpts := *opts
+	fullOpts.Depth = 0
+	fullOpts.Tags = git.AllTags
+
+	r, err = git.Clone(memory.NewStorage(), nil, &fullOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error cloning git repository: %w", err)
+	}
+
+	hash, err := resolveRevision(r, g.Ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, hash, nil
 }
 
-func (g *GitHandler) gatherSchemaTypesForRef(r *git.Repository, ref *plumbing.Reference) ([]*pkg.SchemaType, error) {
-	// Need to resolve the ref first to the right hash otherwise it's not found.
-	hash, err := r.ResolveRevision(plumbing.Revision(ref.Hash().String()))
+// isRefNotFound returns whether err means the remote doesn't have the requested branch. Any other
+// error, like failing authentication or an unreachable remote, won't be fixed by cloning the whole history.
+func isRefNotFound(err error) bool {
+	return errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, git.NoMatchingRefSpecError{})
+}
+
+// checkoutCached updates the cached clone of the repository and resolves the configured ref, tag or
+// the default branch in it. If the ref can't be resolved in the shallow clone, it may be a commit SHA,
+// so the clone is deepened to the whole history.
+func (g *GitHandler) checkoutCached(opts *git.CloneOptions) (*git.Repository, *plumbing.Hash, error) {
+	rev := cmp.Or(g.Ref, g.Tag)
+
+	r, err := g.cachedRepository(opts, rev, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve revision: %w", err)
+		return nil, nil, err
+	}
+
+	hash, err := resolveRevision(r, cmp.Or(rev, remoteHead))
+	if err != nil && g.Ref != "" && !g.cache.Offline() {
+		if r, err = g.cachedRepository(opts, rev, true); err != nil {
+			return nil, nil, err
+		}
+
+		hash, err = resolveRevision(r, rev)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, hash, nil
+}
+
+// remoteHead is the default branch of the remote in the cached clone. HEAD of the clone itself isn't
+// updated by fetching.
+const remoteHead = "refs/remotes/origin/HEAD"
+
+// cachedRepository opens the cached clone of the repository and fetches rev, or the default branch if
+// rev is empty, with depth 1 into it. If full is set, or the cached clone already has the whole history,
+// all branches and tags are fetched with their whole history instead, which later runs fetch
+// incrementally. A shallow clone can't be deepened by fetching, so it's replaced by a full one. In
+// offline mode, nothing is fetched.
+func (g *GitHandler) cachedRepository(opts *git.CloneOptions, rev string, full bool) (*git.Repository, error) {
+	dir := g.cache.GitDir(g.URL)
+
+	r, err := git.PlainOpen(dir)
+	switch {
+	case errors.Is(err, git.ErrRepositoryNotExists):
+		if g.cache.Offline() {
+			return nil, fmt.Errorf("git repository %s is not cached and fetching is disabled in offline mode", g.URL)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to open cached git repository %s: %w", dir, err)
+	case g.cache.Offline():
+		return r, nil
+	default:
+		shallow, err := r.Storer.Shallow()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shallow commits of cached git repository %s: %w", dir, err)
+		}
+
+		_, headErr := r.Reference(remoteHead, false)
+		switch {
+		case len(shallow) == 0 && headErr == nil:
+			// the cached clone has the whole history.
+			full = true
+		case len(shallow) > 0 && full:
+			if err := os.RemoveAll(dir); err != nil {
+				return nil, fmt.Errorf("failed to remove shallow git repository %s: %w", dir, err)
+			}
+
+			r = nil
+		}
+	}
+
+	created := r == nil
+	if created {
+		if r, err = initRepository(dir, g.URL); err != nil {
+			return nil, err
+		}
 	}
 
+	fetchOpts := &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*", "+HEAD:" + remoteHead},
+		Auth:       opts.Auth,
+		CABundle:   opts.CABundle,
+		Tags:       git.AllTags,
+		Force:      true,
+	}
+	if !full {
+		fetchOpts.RefSpecs = []config.RefSpec{shallowRefSpec(g.Tag, rev)}
+		fetchOpts.Tags = git.NoTags
+		fetchOpts.Depth = 1
+	}
+
+	err = r.Fetch(fetchOpts)
+	switch {
+	case err == nil, errors.Is(err, git.NoErrAlreadyUpToDate):
+	case !full && errors.Is(err, git.NoMatchingRefSpecError{}):
+		// the ref isn't a branch. Resolving it decides whether the whole history is needed.
+	default:
+		if created {
+			_ = os.RemoveAll(dir)
+		}
+
+		return nil, fmt.Errorf("error fetching git repository: %w", err)
+	}
+
+	return r, nil
+}
+
+// shallowRefSpec returns the refspec that fetches the tag, the branch rev or the default branch.
+func shallowRefSpec(tag, rev string) config.RefSpec {
+	switch {
+	case tag != "" && tag == rev:
+		return config.RefSpec(fmt.Sprintf("+refs/tags/%s:refs/tags/%s", tag, tag))
+	case rev != "":
+		return config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", rev, rev))
+	default:
+		return config.RefSpec("+HEAD:" + remoteHead)
+	}
+}
+
+// initRepository creates an empty bare repository in dir with url as its origin.
+func initRepository(dir, url string) (*git.Repository, error) {
+	r, err := git.PlainInit(dir, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cached git repository %s: %w", dir, err)
+	}
+
+	if _, err := r.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}}); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, fmt.Errorf("failed to add remote to cached git repository %s: %w", dir, err)
+	}
+
+	return r, nil
+}
+
+// CRDsForRefs clones the repository once and discovers the CRDs at both refs.
+// A ref can be a tag, a branch or a commit SHA.
+func (g *GitHandler) CRDsForRefs(from, to string) (_ []*pkg.SchemaType, _ []*pkg.SchemaType, err error) {
+	secrets := []string{g.Password, g.Token}
+	defer func() {
+		err = credentials.Redact(err, secrets...)
+	}()
+
+	if err := g.compilePatterns(); err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := g.constructGitOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secrets = append(secrets, authSecrets(opts.Auth)...)
+
+	// both refs need to be available in the same clone.
+	opts.Depth = 0
+	opts.Tags = git.AllTags
+
+	if g.cache != nil {
+		defer g.cache.LockGit(g.URL)()
+	}
+
+	var r *git.Repository
+	if err := g.withStoredCredentials(opts, func() (err error) {
+		if g.cache != nil {
+			r, err = g.cachedRepository(opts, "", true)
+
+			return err
+		}
+
+		if r, err = git.Clone(memory.NewStorage(), nil, opts); err != nil {
+			return fmt.Errorf("error cloning git repository: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	fromHash, err := resolveRevision(r, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toHash, err := resolveRevision(r, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fromCRDs, err := g.gatherSchemaTypesForHash(r, fromHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to gather CRDs for %s: %w", from, err)
+	}
+
+	toCRDs, err := g.gatherSchemaTypesForHash(r, toHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to gather CRDs for %s: %w", to, err)
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Discovered number of CRDs at %s: %d, at %s: %d\n", from, len(fromCRDs), to, len(toCRDs))
+
+	return fromCRDs, toCRDs, nil
+}
+
+// resolveRevision resolves a branch, a tag or a commit SHA. Branches are resolved as remote
+// branches first, because other branches than the default branch only exist as remote branches
+// in a fresh clone and local branches aren't updated by fetching.
+func resolveRevision(r *git.Repository, rev string) (*plumbing.Hash, error) {
+	if hash, err := r.ResolveRevision(plumbing.Revision("refs/remotes/origin/" + rev)); err == nil {
+		return hash, nil
+	}
+
+	hash, err := r.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", rev, err)
+	}
+
+	return hash, nil
+}
+
+func (g *GitHandler) gatherSchemaTypesForHash(r *git.Repository, hash *plumbing.Hash) ([]*pkg.SchemaType, error) {
 	commit, err := r.CommitObject(*hash)
 	if err != nil {
 		return nil, fmt.Errorf("error getting commit object: %w", err)
@@ -80,18 +425,28 @@ func (g *GitHandler) gatherSchemaTypesForRef(r *git.Repository, ref *plumbing.Re
 		return nil, err
 	}
 
+	root := strings.Trim(g.Path, "/")
+	if root != "" {
+		commitTree, err = commitTree.Tree(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find path %s: %w", root, err)
+		}
+	}
+
 	var crds []*pkg.SchemaType
 	// Tried to make this concurrent, but there was very little gain. It just takes this long to
 	// clone a large repository. It's not the processing OR the rendering that takes long.
 	if err := commitTree.Files().ForEach(func(f *object.File) error {
-		crd, err := g.processEntry(f)
+		if root != "" {
+			f.Name = root + "/" + f.Name
+		}
+
+		schemaTypes, err := g.processEntry(f)
 		if err != nil {
 			return err
 		}
 
-		if crd != nil {
-			crds = append(crds, crd)
-		}
+		crds = append(crds, schemaTypes...)
 
 		return nil
 	}); err != nil {
@@ -101,15 +456,13 @@ func (g *GitHandler) gatherSchemaTypesForRef(r *git.Repository, ref *plumbing.Re
 	return crds, nil
 }
 
-func (g *GitHandler) processEntry(f *object.File) (*pkg.SchemaType, error) {
-	for _, path := range strings.Split(f.Name, string(filepath.Separator)) {
-		if path == "test" {
-			return nil, nil
-		}
+func (g *GitHandler) processEntry(f *object.File) ([]*pkg.SchemaType, error) {
+	if !g.selected(f.Name) {
+		return nil, nil
 	}
 
-	if ext := filepath.Ext(f.Name); ext != ".yaml" {
-		return nil, nil
+	if binary, err := f.IsBinary(); err != nil || binary {
+		return nil, nil //nolint:nilerr // intentional
 	}
 
 	content, err := f.Contents()
@@ -117,26 +470,47 @@ func (g *GitHandler) processEntry(f *object.File) (*pkg.SchemaType, error) {
 		return nil, err
 	}
 
-	sanitized, err := sanitize.Sanitize([]byte(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to sanitize content: %w", err)
+	if !pkg.ContainsCRD([]byte(content)) {
+		return nil, nil
 	}
 
-	crd := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(sanitized, crd); err != nil {
+	schemaTypes, err := extractSchemaTypes([]byte(content), f.Name, g.group)
+	if err != nil {
 		return nil, nil //nolint:nilerr // intentional
 	}
 
-	schemaType, err := pkg.ExtractSchemaType(crd)
-	if err != nil || schemaType == nil {
-		return nil, nil //nolint:nilerr // intentional
+	return schemaTypes, nil
+}
+
+// compilePatterns compiles the Include and Exclude patterns once, so invalid patterns are reported
+// instead of matching nothing.
+func (g *GitHandler) compilePatterns() (err error) {
+	if g.include, err = glob.CompileAll(g.Include); err != nil {
+		return fmt.Errorf("invalid include pattern: %w", err)
+	}
+
+	if g.exclude, err = glob.CompileAll(g.Exclude); err != nil {
+		return fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	return nil
+}
+
+// selected returns true if the file at path should be considered for discovery.
+func (g *GitHandler) selected(path string) bool {
+	if !g.IncludeTests {
+		for _, segment := range strings.Split(path, "/") {
+			if segment == "test" {
+				return false
+			}
+		}
 	}
 
-	if g.group != "" {
-		schemaType.Rendering = pkg.Rendering{Group: g.group}
+	if len(g.include) > 0 && !glob.MatchAny(g.include, path) {
+		return false
 	}
 
-	return schemaType, nil
+	return !glob.MatchAny(g.exclude, path)
 }
 
 func (g *GitHandler) constructGitOptions() (*git.CloneOptions, error) {
@@ -158,7 +532,12 @@ func (g *GitHandler) constructGitOptions() (*git.CloneOptions, error) {
 		}
 	}
 	if g.caBundle != "" {
-		opts.CABundle = []byte(g.caBundle)
+		bundle, err := os.ReadFile(g.caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		opts.CABundle = bundle
 	}
 	if g.privSSHKey != "" {
 		if !strings.Contains(g.URL, "@") {
@@ -183,6 +562,69 @@ func (g *GitHandler) constructGitOptions() (*git.CloneOptions, error) {
 		}
 		opts.Auth = authMethod
 	}
-
 	return opts, nil
 }
+
+// withStoredCredentials calls fetch and, if it fails because the repository needs authentication and
+// no credentials were passed, calls it again with the credentials stored for the repository. Public
+// repositories are fetched anonymously, so stored credentials aren't sent to them and the git credential
+// helpers aren't asked.
+func (g *GitHandler) withStoredCredentials(opts *git.CloneOptions, fetch func() error) error {
+	err := fetch()
+	if opts.Auth != nil || (!errors.Is(err, transport.ErrAuthenticationRequired) && !errors.Is(err, transport.ErrAuthorizationFailed)) {
+		return err
+	}
+
+	auth, storedErr := g.storedCredentials()
+	if storedErr != nil {
+		return storedErr
+	}
+
+	if auth == nil {
+		return err
+	}
+
+	opts.Auth = auth
+
+	return credentials.Redact(fetch(), authSecrets(auth)...)
+}
+
+// storedCredentials looks up credentials of an HTTP(S) repository in the netrc file and the git credential
+// helpers, so they don't have to be passed as flags. It returns nil if none are found.
+func (g *GitHandler) storedCredentials() (transport.AuthMethod, error) {
+	if !strings.HasPrefix(g.URL, "http://") && !strings.HasPrefix(g.URL, "https://") {
+		return nil, nil
+	}
+
+	u, err := url.Parse(g.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse git URL: %w", err)
+	}
+
+	username, password, ok, err := credentials.Netrc(u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		username, password, ok = credentials.GitHelper(g.URL)
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	return &http.BasicAuth{Username: username, Password: password}, nil
+}
+
+// authSecrets returns the secrets of an auth method, which must not show up in error messages.
+func authSecrets(auth transport.AuthMethod) []string {
+	switch a := auth.(type) {
+	case *http.BasicAuth:
+		return []string{a.Password}
+	case *http.TokenAuth:
+		return []string{a.Token}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+)
+
+func TestIsRefNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "reference not found", err: plumbing.ErrReferenceNotFound, want: true},
+		{name: "no matching ref spec", err: git.NoMatchingRefSpecError{}, want: true},
+		{name: "authentication required", err: transport.ErrAuthenticationRequired},
+		{name: "repository not found", err: transport.ErrRepositoryNotFound},
+		{name: "other", err: errors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRefNotFound(tt.err))
+		})
+	}
+}
+
+// testRepository is a local git repository that is cloned over the file transport.
+type testRepository struct {
+	dir string
+	r   *git.Repository
+	w   *git.Worktree
+}
+
+func newTestRepository(t *testing.T) *testRepository {
+	t.Helper()
+
+	// cloning a local repository runs git-upload-pack.
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	return &testRepository{dir: dir, r: r, w: w}
+}
+
+// commit writes the files and commits them.
+func (tr *testRepository) commit(t *testing.T, message string, files map[string]string) plumbing.Hash {
+	t.Helper()
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tr.dir, name), []byte(content), 0o600))
+		_, err := tr.w.Add(name)
+		require.NoError(t, err)
+	}
+
+	hash, err := tr.w.Commit(message, &git.CommitOptions{
+		Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		AllowEmptyCommits: true,
+	})
+	require.NoError(t, err)
+
+	return hash
+}
+
+func (tr *testRepository) branch(t *testing.T, name string, hash plumbing.Hash) {
+	t.Helper()
+
+	require.NoError(t, tr.r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), hash)))
+}
+
+func (tr *testRepository) tag(t *testing.T, name string, hash plumbing.Hash) {
+	t.Helper()
+
+	_, err := tr.r.CreateTag(name, hash, nil)
+	require.NoError(t, err)
+}
+
+func TestCheckoutRef(t *testing.T) {
+	repo := newTestRepository(t)
+	dir := repo.dir
+
+	first := repo.commit(t, "first", map[string]string{"crd.yaml": "first"})
+	second := repo.commit(t, "second", map[string]string{"crd.yaml": "second"})
+	repo.branch(t, "feature", second)
+
+	tests := []struct {
+		name    string
+		url     string
+		ref     string
+		want    plumbing.Hash
+		wantErr string
+	}{
+		{name: "branch", url: dir, ref: "feature", want: second},
+		{name: "commit SHA falls back to the whole history", url: dir, ref: first.String(), want: first},
+		{name: "missing ref falls back to the whole history", url: dir, ref: "missing", wantErr: "failed to resolve revision missing"},
+		{name: "other errors are returned", url: filepath.Join(dir, "missing"), ref: first.String(), wantErr: "error cloning git repository: repository not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &GitHandler{URL: tt.url, Ref: tt.ref}
+			opts, err := g.constructGitOptions()
+			require.NoError(t, err)
+
+			_, hash, err := g.checkoutRef(opts)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *hash)
+		})
+	}
+}
+
+func TestCheckoutCached(t *testing.T) {
+	repo := newTestRepository(t)
+	first := repo.commit(t, "first", map[string]string{"crd.yaml": "first"})
+	repo.tag(t, "v1", first)
+	second := repo.commit(t, "second", map[string]string{"crd.yaml": "second"})
+
+	checkout := func(t *testing.T, g *GitHandler) (*git.Repository, plumbing.Hash, error) {
+		t.Helper()
+
+		opts, err := g.constructGitOptions()
+		require.NoError(t, err)
+
+		r, hash, err := g.checkout(opts)
+		if err != nil {
+			return nil, plumbing.ZeroHash, err
+		}
+
+		return r, *hash, nil
+	}
+
+	shallow := func(t *testing.T, r *git.Repository) bool {
+		t.Helper()
+
+		commits, err := r.Storer.Shallow()
+		require.NoError(t, err)
+
+		return len(commits) > 0
+	}
+
+	t.Run("tag", func(t *testing.T) {
+		c := cache.New(t.TempDir(), false)
+		for range 2 {
+			r, hash, err := checkout(t, &GitHandler{URL: repo.dir, Tag: "v1", cache: c})
+			require.NoError(t, err)
+			assert.Equal(t, first, hash)
+			assert.True(t, shallow(t, r))
+		}
+	})
+
+	t.Run("head picks up new commits", func(t *testing.T) {
+		c := cache.New(t.TempDir(), false)
+		_, hash, err := checkout(t, &GitHandler{URL: repo.dir, cache: c})
+		require.NoError(t, err)
+		assert.Equal(t, second, hash)
+
+		third := repo.commit(t, "third", nil)
+
+		_, hash, err = checkout(t, &GitHandler{URL: repo.dir, cache: c})
+		require.NoError(t, err)
+		assert.Equal(t, third, hash)
+	})
+
+	t.Run("commit SHA replaces the shallow clone with the whole history", func(t *testing.T) {
+		c := cache.New(t.TempDir(), false)
+		r, _, err := checkout(t, &GitHandler{URL: repo.dir, cache: c})
+		require.NoError(t, err)
+		assert.True(t, shallow(t, r))
+
+		r, hash, err := checkout(t, &GitHandler{URL: repo.dir, Ref: first.String(), cache: c})
+		require.NoError(t, err)
+		assert.Equal(t, first, hash)
+		assert.False(t, shallow(t, r))
+
+		// the whole history is kept, so the tag is found without fetching it again.
+		_, hash, err = checkout(t, &GitHandler{URL: repo.dir, Tag: "v1", cache: c})
+		require.NoError(t, err)
+		assert.Equal(t, first, hash)
+	})
+
+	t.Run("offline without a cached clone", func(t *testing.T) {
+		_, _, err := checkout(t, &GitHandler{URL: repo.dir, cache: cache.New(t.TempDir(), true)})
+		require.ErrorContains(t, err, "is not cached and fetching is disabled in offline mode")
+	})
+
+	t.Run("offline with a cached clone", func(t *testing.T) {
+		dir := t.TempDir()
+		_, cached, err := checkout(t, &GitHandler{URL: repo.dir, cache: cache.New(dir, false)})
+		require.NoError(t, err)
+
+		repo.commit(t, "not fetched", nil)
+
+		_, hash, err := checkout(t, &GitHandler{URL: repo.dir, cache: cache.New(dir, true)})
+		require.NoError(t, err)
+		assert.Equal(t, cached, hash)
+	})
+}
+
+func TestCRDsForRefs(t *testing.T) {
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join("..", "sample-crd", name))
+		require.NoError(t, err)
+
+		return string(content)
+	}
+
+	repo := newTestRepository(t)
+	first := repo.commit(t, "krok", map[string]string{"krok.yaml": read("delivery.krok.app_krokcommands.yaml")})
+	repo.tag(t, "v1", first)
+	second := repo.commit(t, "aws", map[string]string{"aws.yaml": read("infrastructure.cluster.x-k8s.io_awsclusters.yaml")})
+	repo.tag(t, "v2", second)
+	third := repo.commit(t, "prometheus", map[string]string{"prometheus.yaml": read("prometheus.crd.yaml")})
+	repo.branch(t, "feature", third)
+
+	kinds := func(crds []*pkg.SchemaType) []string {
+		var kinds []string
+		for _, crd := range crds {
+			kinds = append(kinds, crd.Kind)
+		}
+
+		return kinds
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		wantFrom []string
+		wantTo   []string
+	}{
+		{name: "tags", from: "v1", to: "v2", wantFrom: []string{"KrokCommand"}, wantTo: []string{"AWSCluster", "KrokCommand"}},
+		{name: "branch", from: "v2", to: "feature", wantFrom: []string{"AWSCluster", "KrokCommand"}, wantTo: []string{"AWSCluster", "KrokCommand", "Prometheus"}},
+		{name: "commit SHA", from: first.String(), to: "feature", wantFrom: []string{"KrokCommand"}, wantTo: []string{"AWSCluster", "KrokCommand", "Prometheus"}},
+	}
+
+	for _, cached := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s cached %t", tt.name, cached), func(t *testing.T) {
+				g := &GitHandler{URL: repo.dir}
+				if cached {
+					g.cache = cache.New(t.TempDir(), false)
+				}
+
+				from, to, err := g.CRDsForRefs(tt.from, tt.to)
+				require.NoError(t, err)
+				assert.ElementsMatch(t, tt.wantFrom, kinds(from))
+				assert.ElementsMatch(t, tt.wantTo, kinds(to))
+			})
+		}
+	}
+}
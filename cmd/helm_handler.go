@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/helm"
+)
+
+// HelmHandler discovers the CRDs of a Helm chart directory or a packaged chart.
+type HelmHandler struct {
+	location string
+	group    string
+}
+
+func (h *HelmHandler) CRDs() ([]*pkg.SchemaType, error) {
+	ch, err := loader.Load(h.location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s: %w", h.location, err)
+	}
+
+	manifests, err := helm.Manifests(ch)
+	if err := warnRenderError(err); err != nil {
+		return nil, err
+	}
+
+	var crds []*pkg.SchemaType
+	for _, manifest := range manifests {
+		schemaTypes, err := extractRenderedSchemaTypes(manifest.Content, manifest.Name, h.group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract CRDs from %s: %w", manifest.Name, err)
+		}
+
+		crds = append(crds, schemaTypes...)
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, "Discovered number of CRDs: ", len(crds))
+
+	return crds, nil
+}
+
+// warnRenderError prints a warning if the templates of a chart couldn't be rendered, so only the CRDs of
+// its crds directories are used. Other errors are returned.
+func warnRenderError(err error) error {
+	var renderErr *helm.RenderError
+	if !errors.As(err, &renderErr) {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Warning: %s. Only the CRDs of the crds directories are used.\n", err)
+
+	return nil
+}
+
+// isChart returns true if the location is a packaged chart or a directory containing a chart.
+func isChart(location string) bool {
+	if strings.HasSuffix(location, ".tgz") {
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(location, "Chart.yaml"))
+
+	return err == nil
+}
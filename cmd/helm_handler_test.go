@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelmHandlerWithTemplatesThatFailToRender(t *testing.T) {
+	crds, err := (&HelmHandler{location: "../pkg/helm/testdata/broken-chart"}).CRDs()
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+	assert.Equal(t, "Widget", crds[0].Kind)
+}
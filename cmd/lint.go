@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/lint"
+)
+
+const OutputSARIF = "sarif"
+
+var (
+	// lintCmd checks CRD schemas for common quality problems.
+	lintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check CRD schemas for common quality problems.",
+		Long: `Check CRD schemas for common quality problems.
+
+The command exits with a non-zero exit code if any finding has at least the severity
+set by --fail-on. Use --list-rules to see the available rules.`,
+		SilenceUsage: true,
+		RunE:         runLint,
+	}
+
+	lintArgs = &lintCmdArgs{}
+)
+
+type lintCmdArgs struct {
+	source     rootArgs
+	output     string
+	enable     []string
+	disable    []string
+	configFile string
+	failOn     string
+	listRules  bool
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	f := lintCmd.PersistentFlags()
+	addSourceFlags(f, &lintArgs.source)
+	f.StringVarP(&lintArgs.output, "output", "o", OutputText, "The format of the report. Options are: text, json, sarif.")
+	f.StringSliceVar(&lintArgs.enable, "enable", nil, "Only run these rules.")
+	f.StringSliceVar(&lintArgs.disable, "disable", nil, "Skip these rules.")
+	f.StringVar(&lintArgs.configFile, "lint-config", "", "A configuration file that selects rules and overrides their severity.")
+	f.StringVar(&lintArgs.failOn, "fail-on", string(lint.SeverityError), "The lowest severity that results in a non-zero exit code. Options are: error, warning, info.")
+	f.BoolVar(&lintArgs.listRules, "list-rules", false, "List all available rules and exit.")
+}
+
+func runLint(_ *cobra.Command, _ []string) (err error) {
+	if lintArgs.listRules {
+		listRules(os.Stdout)
+
+		return nil
+	}
+
+	failOn, err := lint.ParseSeverity(lintArgs.failOn)
+	if err != nil {
+		return err
+	}
+
+	config, err := loadLintConfig()
+	if err != nil {
+		return err
+	}
+
+	linter, err := lint.NewLinter(config)
+	if err != nil {
+		return err
+	}
+
+	crdHandler, err := constructHandler(&lintArgs.source)
+	if err != nil {
+		return err
+	}
+
+	crds, partial, err := loadCRDs(crdHandler)
+	if err != nil {
+		return fmt.Errorf("failed to load CRDs: %w", err)
+	}
+
+	defer func() {
+		err = reportPartialLoad(partial, err)
+	}()
+
+	findings := linter.Lint(crds)
+
+	switch lintArgs.output {
+	case OutputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return fmt.Errorf("failed to encode findings: %w", err)
+		}
+	case OutputSARIF:
+		if err := lint.WriteSARIF(os.Stdout, linter.Rules(), findings); err != nil {
+			return fmt.Errorf("failed to write sarif output: %w", err)
+		}
+	case OutputText:
+		displayFindings(os.Stdout, findings)
+	default:
+		return fmt.Errorf("unknown output format %s", lintArgs.output)
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if f.Severity.AtLeast(failOn) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d finding(s) with severity %s or higher", failed, failOn)
+	}
+
+	return nil
+}
+
+// loadLintConfig reads the optional configuration file and merges the rule selection flags into it.
+func loadLintConfig() (lint.Config, error) {
+	config := lint.Config{}
+
+	if lintArgs.configFile != "" {
+		content, err := os.ReadFile(lintArgs.configFile)
+		if err != nil {
+			return config, fmt.Errorf("failed to read lint config: %w", err)
+		}
+
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			return config, fmt.Errorf("failed to unmarshal lint config: %w", err)
+		}
+	}
+
+	config.Enable = append(config.Enable, lintArgs.enable...)
+	config.Disable = append(config.Disable, lintArgs.disable...)
+
+	return config, nil
+}
+
+func listRules(w io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Rule", "Severity", "Description"})
+	for _, r := range lint.DefaultRules {
+		t.AppendRow(table.Row{r.Name, r.Severity, r.Description})
+	}
+	t.Render()
+}
+
+func displayFindings(w io.Writer, findings []lint.Finding) {
+	if len(findings) == 0 {
+		_, _ = fmt.Fprintln(w, "No problems found.")
+
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Severity", "Rule", "Kind", "Version", "Path", "Message"})
+	rows := make([]table.Row, 0, len(findings))
+	counts := map[lint.Severity]int{}
+	for _, f := range findings {
+		counts[f.Severity]++
+
+		var severity string
+		switch f.Severity {
+		case lint.SeverityError:
+			severity = color.RedString(string(f.Severity))
+		case lint.SeverityWarning:
+			severity = color.YellowString(string(f.Severity))
+		case lint.SeverityInfo:
+			severity = color.CyanString(string(f.Severity))
+		}
+
+		rows = append(rows, table.Row{
+			severity, f.Rule, f.Group + "/" + f.Kind, f.Version, f.Path, text.WrapText(f.Message, wrapLen),
+		})
+	}
+	t.AppendRows(rows)
+	t.Render()
+
+	_, _ = fmt.Fprintf(w, "\nFindings total: %d, errors: %d, warnings: %d, info: %d\n",
+		len(findings), counts[lint.SeverityError], counts[lint.SeverityWarning], counts[lint.SeverityInfo])
+}
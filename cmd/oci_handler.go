@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/oci"
+)
+
+// OCIHandler discovers the CRDs of a Helm chart or a CRD bundle stored as an OCI artifact.
+type OCIHandler struct {
+	reference string
+	username  string
+	password  string
+	token     string
+	plainHTTP bool
+	group     string
+}
+
+func (h *OCIHandler) CRDs() ([]*pkg.SchemaType, error) {
+	manifests, err := oci.Manifests(context.Background(), h.reference, oci.Options{
+		Username:  h.username,
+		Password:  h.password,
+		Token:     h.token,
+		PlainHTTP: h.plainHTTP,
+	})
+	if err := warnRenderError(err); err != nil {
+		return nil, credentials.Redact(err, h.password, h.token)
+	}
+
+	var crds []*pkg.SchemaType
+	for _, manifest := range manifests {
+		schemaTypes, err := extractRenderedSchemaTypes(manifest.Content, manifest.Name, h.group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract CRDs from %s: %w", manifest.Name, err)
+		}
+
+		crds = append(crds, schemaTypes...)
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, "Discovered number of CRDs: ", len(crds))
+
+	return crds, nil
+}
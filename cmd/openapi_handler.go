@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/openapi"
+)
+
+// OpenAPIHandler discovers the kinds described by OpenAPI v3 documents served by the apiserver at
+// /openapi/v3. The location can be a document or a folder of documents.
+type OpenAPIHandler struct {
+	location string
+	group    string
+}
+
+func (h *OpenAPIHandler) CRDs() ([]*pkg.SchemaType, error) {
+	var schemaTypes []*pkg.SchemaType
+
+	if err := filepath.WalkDir(h.location, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		if !openapi.IsDocument(content) {
+			return nil
+		}
+
+		documentTypes, err := openapi.SchemaTypes(content)
+		if err != nil {
+			return fmt.Errorf("failed to read OpenAPI document %s: %w", path, err)
+		}
+
+		for _, schemaType := range documentTypes {
+			schemaType.Location = path
+
+			if h.group != "" {
+				schemaType.Rendering = pkg.Rendering{Group: h.group}
+			}
+		}
+
+		schemaTypes = append(schemaTypes, documentTypes...)
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI documents: %w", err)
+	}
+
+	schemaTypes = openapi.Merge(schemaTypes)
+
+	_, _ = fmt.Fprintln(os.Stderr, "Discovered number of kinds: ", len(schemaTypes))
+
+	return schemaTypes, nil
+}
+
+// isOpenAPIDocument returns true if the file is an OpenAPI v3 document.
+func isOpenAPIDocument(location string) bool {
+	content, err := os.ReadFile(location)
+	if err != nil {
+		return false
+	}
+
+	return openapi.IsDocument(content)
+}
@@ -41,7 +41,7 @@ func init() {
 	f.StringVarP(&schemaArgs.outputFolder, "output", "o", ".", "output location of the generated schema files")
 }
 
-func runGenerateSchema(_ *cobra.Command, _ []string) error {
+func runGenerateSchema(_ *cobra.Command, _ []string) (err error) {
 	crdHandler, err := constructHandler(args)
 	if err != nil {
 		return err
@@ -57,12 +57,20 @@ func runGenerateSchema(_ *cobra.Command, _ []string) error {
 		schemaArgs.outputFolder = filepath.Dir(loc)
 	}
 
-	crds, err := crdHandler.CRDs()
+	crds, partial, err := loadCRDs(crdHandler)
 	if err != nil {
 		return fmt.Errorf("failed to load CRDs: %w", err)
 	}
 
+	defer func() {
+		err = reportPartialLoad(partial, err)
+	}()
+
 	for _, crd := range crds {
+		if err := crd.ResolveRefs(); err != nil {
+			return fmt.Errorf("failed to resolve references of %s: %w", crd.Kind, err)
+		}
+
 		for _, v := range crd.Versions {
 			if v.Schema.ID == "" {
 				v.Schema.ID = "https://crdtoyaml.com/" + crd.Kind + "." + crd.Group + "." + v.Name + ".schema.json"
@@ -2,18 +2,23 @@ package cmd
 
 import (
 	"fmt"
-	"net/http"
-	"time"
-
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/yaml"
+	"maps"
+	neturl "net/url"
+	"strings"
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/credentials"
 	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
-	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/provider"
 )
 
-const timeout = 10
+// httpOpts configure how the content of a URL is fetched.
+type httpOpts struct {
+	client  fetcher.ClientOptions
+	headers map[string]string
+	retries int
+}
 
 type URLHandler struct {
 	url      string
@@ -21,39 +26,115 @@ type URLHandler struct {
 	password string
 	token    string
 	group    string
+	cache    *cache.Cache
+	http     httpOpts
 }
 
-func (h *URLHandler) CRDs() ([]*pkg.SchemaType, error) {
-	client := http.DefaultClient
-	client.Timeout = timeout * time.Second
+func (h *URLHandler) CRDs() (_ []*pkg.SchemaType, err error) {
+	secrets := append([]string{h.password, h.token}, secretHeaderValues(h.http.headers)...)
+	defer func() {
+		err = credentials.Redact(err, secrets...)
+	}()
 
-	f := fetcher.NewFetcher(client, h.username, h.password, h.token)
-	content, err := f.Fetch(h.url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch content: %w", err)
+	url, headers := h.url, h.http.headers
+
+	// the web pages of files and directories of GitHub and GitLab repositories are HTML, so the
+	// content is fetched from the raw URL, the API or the repository instead.
+	if loc, ok := provider.Parse(h.url, provider.Providers); ok {
+		if loc.Tree {
+			return h.treeCRDs(loc)
+		}
+
+		url = loc.RawURL()
+		if h.token != "" {
+			var apiHeaders map[string]string
+			url, apiHeaders = loc.APIURL()
+			headers = maps.Clone(headers)
+			if headers == nil {
+				headers = map[string]string{}
+			}
+
+			maps.Copy(headers, apiHeaders)
+		}
 	}
 
-	content, err = sanitize.Sanitize(content)
+	username, password := h.username, h.password
+	if username == "" && password == "" && h.token == "" {
+		if username, password, err = netrcCredentials(url); err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, password)
+	}
+
+	client, err := fetcher.NewClient(h.http.client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sanitize content: %w", err)
+		return nil, fmt.Errorf("failed to construct http client: %w", err)
 	}
 
-	crd := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal(content, crd); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal into custom resource definition: %w", err)
+	f := fetcher.NewFetcher(client, username, password, h.token).
+		WithHeaders(headers).
+		WithRetries(h.http.retries, retryBackoff)
+	if h.cache != nil {
+		f.WithCache(h.cache)
 	}
-	schemaType, err := pkg.ExtractSchemaType(crd)
+
+	content, err := f.Fetch(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract schema type: %w", err)
+		return nil, fmt.Errorf("failed to fetch content: %w", err)
 	}
 
-	if schemaType == nil {
-		return nil, nil
+	return extractSchemaTypes(content, h.url, h.group)
+}
+
+// treeCRDs discovers the CRDs in the directory of a repository.
+func (h *URLHandler) treeCRDs(loc *provider.Location) ([]*pkg.SchemaType, error) {
+	handler := &GitHandler{
+		URL:      loc.CloneURL(),
+		Username: h.username,
+		Password: h.password,
+		Ref:      loc.Ref,
+		Path:     loc.Path,
+		caBundle: h.http.client.CABundle,
+		group:    h.group,
+		cache:    h.cache,
+	}
+
+	// git doesn't accept tokens as bearer tokens, but as the password of a provider specific user.
+	if h.token != "" {
+		handler.Username = loc.TokenUsername()
+		handler.Password = h.token
+	}
+
+	return handler.CRDs()
+}
+
+// netrcCredentials returns the login and password of the host of url in the netrc file.
+func netrcCredentials(url string) (string, string, error) {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse url: %w", err)
 	}
 
-	if h.group != "" {
-		schemaType.Rendering = pkg.Rendering{Group: h.group}
+	username, password, _, err := credentials.Netrc(u.Host)
+
+	return username, password, err
+}
+
+// secretHeaderValues returns the values of headers whose names suggest they carry credentials, like
+// Authorization or X-Api-Key.
+func secretHeaderValues(headers map[string]string) []string {
+	var secrets []string
+	for name, value := range headers {
+		name = strings.ToLower(name)
+		for _, s := range []string{"auth", "token", "key", "secret", "password", "cookie"} {
+			if strings.Contains(name, s) {
+				secrets = append(secrets, value)
+
+				break
+			}
+		}
 	}
 
-	return []*pkg.SchemaType{schemaType}, nil
+	return secrets
 }
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/validate"
+)
+
+var (
+	// validateCmd validates manifests against the schemas of their CRDs.
+	validateCmd = &cobra.Command{
+		Use:   "validate --crds location manifests...",
+		Short: "Validate manifests against the schemas of their CRDs.",
+		Long: `Validate manifests against the schemas of their CRDs.
+
+The CRDs are loaded from the location given with --crds, which can be a file, a folder,
+a Helm chart, a config file, a URL, a git repository or an OCI artifact. Manifests can be
+files or folders containing multi-document YAML or JSON files, or - to read them from stdin.
+Every object is validated against the version of the CRD that matches its apiVersion and kind.
+Objects of built-in kinds are skipped. The command exits with a non-zero exit code if any object is invalid
+or no CRD was found for it.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runValidate,
+	}
+
+	validateArgs = &validateCmdArgs{}
+)
+
+type validateCmdArgs struct {
+	source rootArgs
+	crds   string
+	output string
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	f := validateCmd.PersistentFlags()
+	f.StringVar(&validateArgs.crds, "crds", "", "The location of the CRDs. A file, a folder, a Helm chart, a config file, a URL, a git repository or an OCI artifact.")
+	f.StringVarP(&validateArgs.output, "output", "o", OutputText, "The format of the report. Options are: text, json.")
+	addSourceOptionFlags(f, &validateArgs.source)
+}
+
+func runValidate(_ *cobra.Command, manifests []string) (err error) {
+	if validateArgs.crds == "" {
+		return errors.New("--crds must be set")
+	}
+
+	if validateArgs.crds == stdinLocation && slices.Contains(manifests, stdinLocation) {
+		return errors.New("the CRDs and the manifests can't both be read from stdin")
+	}
+
+	handler, err := handlerForLocation(validateArgs.crds, &validateArgs.source)
+	if err != nil {
+		return err
+	}
+
+	crds, partial, err := loadCRDs(handler)
+	if err != nil {
+		return fmt.Errorf("failed to load CRDs: %w", err)
+	}
+
+	defer func() {
+		err = reportPartialLoad(partial, err)
+	}()
+
+	validator := validate.NewValidator(crds)
+
+	var results []validate.Result
+	for _, manifest := range manifests {
+		if manifest == stdinLocation {
+			stdinResults, err := validator.Validate("stdin", os.Stdin)
+			if err != nil {
+				return err
+			}
+
+			results = append(results, stdinResults...)
+
+			continue
+		}
+
+		files, err := manifestFiles(manifest)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			fileResults, err := validateFile(validator, file)
+			if err != nil {
+				return err
+			}
+
+			results = append(results, fileResults...)
+		}
+	}
+
+	switch validateArgs.output {
+	case OutputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return fmt.Errorf("failed to encode results: %w", err)
+		}
+	case OutputText:
+		displayValidationResults(os.Stdout, results)
+	default:
+		return fmt.Errorf("unknown output format %s", validateArgs.output)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == validate.StatusInvalid || r.Status == validate.StatusUnknown {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d object(s) failed validation", failed)
+	}
+
+	return nil
+}
+
+// manifestFiles returns the location if it's a file or all YAML and JSON files in it if it's a folder.
+func manifestFiles(location string) ([]string, error) {
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find manifest %s: %w", location, err)
+	}
+
+	if !info.IsDir() {
+		return []string{location}, nil
+	}
+
+	var files []string
+	if err := filepath.WalkDir(location, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, path)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to walk the manifest folder: %w", err)
+	}
+
+	return files, nil
+}
+
+func validateFile(validator *validate.Validator, file string) ([]validate.Result, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	return validator.Validate(file, f)
+}
+
+func displayValidationResults(w io.Writer, results []validate.Result) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"Status", "File", "Document", "Kind", "Name", "Path", "Error"})
+
+	counts := map[validate.Status]int{}
+	for _, r := range results {
+		counts[r.Status]++
+
+		switch r.Status {
+		case validate.StatusValid, validate.StatusSkipped:
+			continue
+		case validate.StatusUnknown:
+			t.AppendRow(table.Row{
+				color.YellowString(string(r.Status)), r.File, r.Document, r.APIVersion + "/" + r.Kind, r.Name, "", "no CRD found for this kind and version",
+			})
+		case validate.StatusInvalid:
+			for _, e := range r.Errors {
+				t.AppendRow(table.Row{
+					color.RedString(string(r.Status)), r.File, r.Document, r.APIVersion + "/" + r.Kind, r.Name, e.Path, text.WrapText(e.Message, wrapLen),
+				})
+			}
+		}
+	}
+
+	if counts[validate.StatusInvalid]+counts[validate.StatusUnknown] > 0 {
+		t.Render()
+		_, _ = fmt.Fprintln(w)
+	}
+
+	_, _ = fmt.Fprintf(w, "Objects total: %d, valid: %d, invalid: %d, unknown: %d, skipped built-in: %d\n",
+		len(results), counts[validate.StatusValid], counts[validate.StatusInvalid], counts[validate.StatusUnknown], counts[validate.StatusSkipped])
+}
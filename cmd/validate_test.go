@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandsAcceptTheSourceOptions(t *testing.T) {
+	options := pflag.NewFlagSet("options", pflag.ContinueOnError)
+	addSourceOptionFlags(options, &rootArgs{})
+
+	for _, cmd := range []*cobra.Command{crdCmd, schemaCmd, lintCmd, checkCmd, diffCmd, validateCmd} {
+		t.Run(cmd.Name(), func(t *testing.T) {
+			options.VisitAll(func(f *pflag.Flag) {
+				assert.NotNil(t, cmd.Flag(f.Name), "missing flag --%s", f.Name)
+			})
+		})
+	}
+}
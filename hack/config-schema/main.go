@@ -0,0 +1,97 @@
+// config-schema generates the JSON schema of the config file from the types in cmd/config_file_schema.go.
+// The descriptions of the schema are the doc comments of the types and their fields.
+//
+// Usage: go run ./hack/config-schema > config.schema.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/Skarlso/crd-to-sample-yaml/cmd"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/strict"
+)
+
+const source = "cmd/config_file_schema.go"
+
+func main() {
+	if err := run(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	descriptions, err := comments(source)
+	if err != nil {
+		return err
+	}
+
+	schema := strict.Schema(reflect.TypeOf(cmd.RenderConfig{}), descriptions)
+	schema.Schema = "http://json-schema.org/draft-04/schema#"
+	schema.ID = "https://raw.githubusercontent.com/Skarlso/crd-to-sample-yaml/main/config.schema.json"
+	schema.Title = "cty config file"
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(schema)
+}
+
+// comments returns the doc comments of the types in the file by their name and of their fields by
+// Type.Field. A field without a comment shares the comment of the field directly above it, so fields
+// documented together like "Minimal, Comments and NoRandom override..." are all described.
+func comments(file string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	descriptions := map[string]string{}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec) //nolint:forcetypeassert // type declarations only contain type specs
+			descriptions[typeSpec.Name.Name] = text(gen.Doc)
+
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			var previous string
+			previousLine := -1
+			for _, field := range structType.Fields.List {
+				description := text(field.Doc)
+				line := fset.Position(field.Pos()).Line
+				if description == "" && line == previousLine+1 {
+					description = previous
+				}
+
+				for _, name := range field.Names {
+					descriptions[typeSpec.Name.Name+"."+name.Name] = description
+				}
+
+				previous, previousLine = description, fset.Position(field.End()).Line
+			}
+		}
+	}
+
+	return descriptions, nil
+}
+
+// text returns the comment as a single line.
+func text(group *ast.CommentGroup) string {
+	return strings.Join(strings.Fields(group.Text()), " ")
+}
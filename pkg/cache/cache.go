@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	dirName   = "cty"
+	httpDir   = "http"
+	gitDir    = "git"
+	dirPerm   = 0o755
+	filePerm  = 0o600
+	metaExt   = ".json"
+	bodyExt   = ".body"
+	keyLength = 16
+)
+
+// Cache stores git repositories and fetched URL content on disk so they don't have
+// to be downloaded again on every run.
+type Cache struct {
+	dir     string
+	offline bool
+
+	mu sync.Mutex
+	// gitLocks serialise the access to a cached repository by sources loaded concurrently.
+	gitLocks map[string]*sync.Mutex
+}
+
+// DefaultDir returns the cache directory of cty in the user's cache directory, which is
+// $XDG_CACHE_HOME on Linux.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, dirName), nil
+}
+
+// New creates a cache in dir. In offline mode, nothing is downloaded and only cached content is used.
+func New(dir string, offline bool) *Cache {
+	return &Cache{dir: dir, offline: offline, gitLocks: map[string]*sync.Mutex{}}
+}
+
+// Offline returns true if only cached content should be used.
+func (c *Cache) Offline() bool {
+	return c.offline
+}
+
+// GitDir returns the directory in which the repository at url is cached.
+func (c *Cache) GitDir(url string) string {
+	return filepath.Join(c.dir, gitDir, key(url))
+}
+
+// LockGit locks the cached repository of url until the returned function is called, so it isn't
+// fetched into while another source reads from it.
+func (c *Cache) LockGit(url string) func() {
+	c.mu.Lock()
+	lock, ok := c.gitLocks[url]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.gitLocks[url] = lock
+	}
+	c.mu.Unlock()
+
+	lock.Lock()
+
+	return lock.Unlock
+}
+
+type entry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Get returns the cached content of url together with the validators that were sent when it was fetched.
+func (c *Cache) Get(url string) ([]byte, string, string, bool) {
+	base := filepath.Join(c.dir, httpDir, key(url))
+
+	meta, err := os.ReadFile(base + metaExt)
+	if err != nil {
+		return nil, "", "", false
+	}
+
+	e := entry{}
+	if err := json.Unmarshal(meta, &e); err != nil || e.URL != url {
+		return nil, "", "", false
+	}
+
+	content, err := os.ReadFile(base + bodyExt)
+	if err != nil {
+		return nil, "", "", false
+	}
+
+	return content, e.ETag, e.LastModified, true
+}
+
+// Put stores the content of url together with its ETag and Last-Modified validators.
+func (c *Cache) Put(url string, content []byte, etag, lastModified string) error {
+	dir := filepath.Join(c.dir, httpDir)
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	meta, err := json.Marshal(entry{URL: url, ETag: etag, LastModified: lastModified})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	base := filepath.Join(dir, key(url))
+	if err := writeFile(base+bodyExt, content); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := writeFile(base+metaExt, meta); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// writeFile writes content to a temporary file that is renamed to name, so concurrent readers and
+// writers of the same entry never see a partially written file. Temporary files are created with filePerm.
+func writeFile(name string, content []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+func key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return hex.EncodeToString(sum[:keyLength])
+}
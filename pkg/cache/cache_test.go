@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutAndGet(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, false)
+
+	_, _, _, ok := c.Get("https://example.com/crd.yaml")
+	assert.False(t, ok)
+
+	require.NoError(t, c.Put("https://example.com/crd.yaml", []byte("kind: Bar"), `"1"`, "yesterday"))
+	require.NoError(t, c.Put("https://example.com/crd.yaml", []byte("kind: Baz"), `"2"`, "today"))
+
+	content, etag, lastModified, ok := c.Get("https://example.com/crd.yaml")
+	require.True(t, ok)
+	assert.Equal(t, "kind: Baz", string(content))
+	assert.Equal(t, `"2"`, etag)
+	assert.Equal(t, "today", lastModified)
+
+	// no temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Join(dir, httpDir))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestLockGit(t *testing.T) {
+	c := New(t.TempDir(), false)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		holders int
+		maximum int
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer c.LockGit("https://example.com/repository.git")()
+
+			mu.Lock()
+			holders++
+			maximum = max(maximum, holders)
+			mu.Unlock()
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, maximum)
+
+	// other repositories aren't locked.
+	unlock := c.LockGit("https://example.com/repository.git")
+	defer unlock()
+	c.LockGit("https://example.com/other.git")()
+}
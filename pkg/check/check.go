@@ -0,0 +1,294 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/validation"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"k8s.io/apiserver/pkg/cel/environment"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/convert"
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// Problem is a single error the apiserver would reject the CRD with.
+type Problem struct {
+	Group    string `json:"group"`
+	Kind     string `json:"kind"`
+	Version  string `json:"version"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+	Location string `json:"location,omitempty"`
+}
+
+// RuleCost is the estimated cost of a single CEL rule.
+type RuleCost struct {
+	Group   string `json:"group"`
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	// Cost is the worst case cost of the rule multiplied by the number of times it can be called.
+	Cost uint64 `json:"cost"`
+}
+
+// Result contains all problems and the estimated cost of every CEL rule that compiled.
+type Result struct {
+	Problems []Problem  `json:"problems"`
+	Costs    []RuleCost `json:"costs"`
+}
+
+// Check runs the same structural schema, defaulting and CEL validation for every version of
+// every CRD that the apiserver runs when a CRD is created.
+func Check(ctx context.Context, crds []*pkg.SchemaType) (*Result, error) {
+	result := &Result{}
+
+	for _, crd := range crds {
+		for _, v := range crd.VersionsOrValidation() {
+			definition, err := toCustomResourceDefinition(crd, v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s/%s version %s: %w", crd.Group, crd.Kind, v.Name, err)
+			}
+
+			for _, e := range validation.ValidateCustomResourceDefinition(ctx, definition) {
+				path, ok := schemaPath(e.Field)
+				if !ok {
+					// errors outside the schema are caused by the CRD we had to construct.
+					continue
+				}
+
+				result.Problems = append(result.Problems, Problem{
+					Group:    crd.Group,
+					Kind:     crd.Kind,
+					Version:  v.Name,
+					Path:     path,
+					Message:  message(e),
+					Location: crd.Location,
+				})
+			}
+
+			schema := definition.Spec.Validation.OpenAPIV3Schema
+			for _, c := range ruleCosts(schema, validation.RootCELContext(schema), "") {
+				c.Group = crd.Group
+				c.Kind = crd.Kind
+				c.Version = v.Name
+				result.Costs = append(result.Costs, c)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// toCustomResourceDefinition constructs a valid CRD with a single version around the schema so the
+// apiserver validation can be used as is. Every version is checked on its own, that way every error
+// belongs to exactly one version.
+func toCustomResourceDefinition(crd *pkg.SchemaType, v *pkg.CRDVersion) (*apiextensions.CustomResourceDefinition, error) {
+	schema, err := convertSchema(v.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	plural := crd.Plural
+	if plural == "" {
+		plural = strings.ToLower(crd.Kind) + "s"
+	}
+
+	scope := apiextensions.NamespaceScoped
+	if crd.Scope == string(apiextensions.ClusterScoped) {
+		scope = apiextensions.ClusterScoped
+	}
+
+	// the name of the version doesn't matter for the validation of the schema.
+	const versionName = "v1"
+
+	return &apiextensions.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: plural + "." + crd.Group,
+		},
+		Spec: apiextensions.CustomResourceDefinitionSpec{
+			Group: crd.Group,
+			Names: apiextensions.CustomResourceDefinitionNames{
+				Plural:   plural,
+				Singular: strings.ToLower(crd.Kind),
+				Kind:     crd.Kind,
+				ListKind: crd.Kind + "List",
+			},
+			Scope:                 scope,
+			Versions:              []apiextensions.CustomResourceDefinitionVersion{{Name: versionName, Served: true, Storage: true}},
+			Validation:            &apiextensions.CustomResourceValidation{OpenAPIV3Schema: schema},
+			Subresources:          toSubresources(v.Subresources),
+			PreserveUnknownFields: ptr(false),
+			Conversion:            &apiextensions.CustomResourceConversion{Strategy: apiextensions.NoneConverter},
+		},
+		Status: apiextensions.CustomResourceDefinitionStatus{
+			StoredVersions: []string{versionName},
+		},
+	}, nil
+}
+
+// message leaves out the value of the error, which for CEL rules is the whole rule struct.
+func message(e *field.Error) string {
+	if e.Detail == "" {
+		return e.ErrorBody()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Type, e.Detail)
+}
+
+// convertSchema converts the schema and removes the empty kind and apiVersion fields that are added
+// during extraction so a sample can be generated. The apiserver would reject those as they have no type.
+func convertSchema(schema *v1beta1.JSONSchemaProps) (*apiextensions.JSONSchemaProps, error) {
+	internal, err := convert.ToInternalSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"kind", "apiVersion"} {
+		if prop, ok := internal.Properties[name]; ok && reflect.DeepEqual(prop, apiextensions.JSONSchemaProps{}) {
+			delete(internal.Properties, name)
+		}
+	}
+
+	return internal, nil
+}
+
+func toSubresources(subresources *pkg.Subresources) *apiextensions.CustomResourceSubresources {
+	if subresources == nil {
+		return nil
+	}
+
+	result := &apiextensions.CustomResourceSubresources{}
+	if subresources.Status {
+		result.Status = &apiextensions.CustomResourceSubresourceStatus{}
+	}
+
+	if subresources.Scale != nil {
+		result.Scale = &apiextensions.CustomResourceSubresourceScale{
+			SpecReplicasPath:   subresources.Scale.SpecReplicasPath,
+			StatusReplicasPath: subresources.Scale.StatusReplicasPath,
+		}
+
+		if subresources.Scale.LabelSelectorPath != "" {
+			result.Scale.LabelSelectorPath = ptr(subresources.Scale.LabelSelectorPath)
+		}
+	}
+
+	return result
+}
+
+var schemaSegment = regexp.MustCompile(`\.properties\[[^\]]+\]|\.items\b|\.additionalProperties\b`)
+
+// schemaPath returns the path inside the schema of an error. Paths are displayed the same way
+// as in the rest of cty, for example .spec.containers[*].name.
+func schemaPath(path string) (string, bool) {
+	const prefix = "spec.validation.openAPIV3Schema"
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+
+	rest := strings.TrimPrefix(path, prefix)
+
+	// segments are replaced in a single pass, so properties called items stay intact.
+	rest = schemaSegment.ReplaceAllStringFunc(rest, func(segment string) string {
+		switch segment {
+		case ".items":
+			return "[*]"
+		case ".additionalProperties":
+			return ".*"
+		}
+
+		return "." + strings.TrimSuffix(strings.TrimPrefix(segment, ".properties["), "]")
+	})
+
+	if rest == "" {
+		rest = "."
+	}
+
+	return rest, true
+}
+
+// ruleCosts compiles the rules of every schema node and estimates their cost the same way the apiserver does.
+func ruleCosts(schema *apiextensions.JSONSchemaProps, celContext *validation.CELSchemaContext, path string) []RuleCost {
+	if schema == nil {
+		return nil
+	}
+
+	var result []RuleCost
+
+	if len(schema.XValidations) > 0 {
+		typeInfo, err := celContext.TypeInfo()
+		if err == nil && typeInfo != nil {
+			envSet := environment.MustBaseEnvSet(environment.DefaultCompatibilityVersion(), true)
+			compiled, err := cel.Compile(typeInfo.Schema, typeInfo.DeclType, celconfig.PerCallLimit, envSet, cel.NewExpressionsEnvLoader())
+			if err == nil {
+				p := path
+				if p == "" {
+					p = "."
+				}
+
+				for i, c := range compiled {
+					if c.Error != nil {
+						// reported by the validation already.
+						continue
+					}
+
+					cardinality := c.MaxCardinality
+					if celContext.MaxCardinality != nil {
+						cardinality = *celContext.MaxCardinality
+					}
+
+					result = append(result, RuleCost{
+						Path: p,
+						Rule: schema.XValidations[i].Rule,
+						Cost: multiply(c.MaxCost, cardinality),
+					})
+				}
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		prop := schema.Properties[name]
+		result = append(result, ruleCosts(&prop, celContext.ChildPropertyContext(&prop, name), path+"."+name)...)
+	}
+
+	if schema.Items != nil && schema.Items.Schema != nil {
+		result = append(result, ruleCosts(schema.Items.Schema, celContext.ChildItemsContext(schema.Items.Schema), path+"[*]")...)
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		additional := schema.AdditionalProperties.Schema
+		result = append(result, ruleCosts(additional, celContext.ChildAdditionalPropertiesContext(additional), path+".*")...)
+	}
+
+	return result
+}
+
+func multiply(a, b uint64) uint64 {
+	if a == 0 {
+		return 0
+	}
+
+	if math.MaxUint64/a < b {
+		return math.MaxUint64
+	}
+
+	return a * b
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
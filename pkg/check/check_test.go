@@ -0,0 +1,94 @@
+package check
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestCheck(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "crd.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+	crds[0].Location = "crd.yaml"
+
+	result, err := Check(context.Background(), crds)
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(result.Problems))
+	for _, p := range result.Problems {
+		assert.Equal(t, "v1", p.Version)
+		assert.Equal(t, "crd.yaml", p.Location)
+		paths = append(paths, p.Path)
+	}
+
+	assert.Equal(t, []string{".spec.items[*].x-kubernetes-validations[0].rule"}, paths)
+
+	require.Len(t, result.Costs, 1)
+	assert.Equal(t, ".spec", result.Costs[0].Path)
+	assert.Equal(t, "self.name.size() > 1", result.Costs[0].Rule)
+	assert.NotZero(t, result.Costs[0].Cost)
+}
+
+func TestCheckCostExceeded(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "cost_exceeded.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+
+	result, err := Check(context.Background(), crds)
+	require.NoError(t, err)
+	require.Len(t, result.Problems, 6)
+	assert.Equal(t, "v1", result.Problems[0].Version)
+	assert.Equal(t, "v2", result.Problems[3].Version)
+	assert.Equal(t, ".spec.x-kubernetes-validations[0].rule", result.Problems[0].Path)
+	assert.Contains(t, result.Problems[0].Message, "estimated rule cost exceeds budget")
+	assert.Equal(t, ".", result.Problems[2].Path)
+	require.Len(t, result.Costs, 2)
+}
+
+func TestCheckNonStructural(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "non_structural.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+
+	result, err := Check(context.Background(), crds)
+	require.NoError(t, err)
+	require.Len(t, result.Problems, 1)
+	assert.Equal(t, ".spec.untyped.type", result.Problems[0].Path)
+	assert.Equal(t, "Required value: must not be empty for specified object fields", result.Problems[0].Message)
+}
+
+func TestCheckValid(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "valid.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+
+	result, err := Check(context.Background(), crds)
+	require.NoError(t, err)
+	assert.Empty(t, result.Problems)
+	assert.Empty(t, result.Costs)
+}
+
+func TestSchemaPath(t *testing.T) {
+	path, ok := schemaPath("spec.validation.openAPIV3Schema.properties[spec].properties[items].items.additionalProperties.type")
+	require.True(t, ok)
+	assert.Equal(t, ".spec.items[*].*.type", path)
+
+	_, ok = schemaPath("spec.names.plural")
+	assert.False(t, ok)
+}
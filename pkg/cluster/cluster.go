@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+// pageSize is the number of CRDs requested at once.
+const pageSize = 100
+
+// Options select the CRDs to discover.
+type Options struct {
+	// Groups are the API groups of the CRDs. If empty, CRDs of all groups are discovered.
+	Groups []string
+	// LabelSelector selects CRDs by their labels.
+	LabelSelector string
+}
+
+// CRDs lists the CRDs installed in a cluster and extracts their schema types.
+func CRDs(ctx context.Context, client apiextensionsclient.CustomResourceDefinitionInterface, opts Options) ([]*pkg.SchemaType, error) {
+	var (
+		crds []*pkg.SchemaType
+		next string
+	)
+
+	for {
+		list, err := client.List(ctx, metav1.ListOptions{
+			LabelSelector: opts.LabelSelector,
+			Limit:         pageSize,
+			Continue:      next,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list CRDs: %w", err)
+		}
+
+		for i := range list.Items {
+			schemaType, err := extractSchemaType(&list.Items[i], opts)
+			if err != nil {
+				return nil, err
+			}
+
+			if schemaType != nil {
+				crds = append(crds, schemaType)
+			}
+		}
+
+		next = list.Continue
+		if next == "" {
+			return crds, nil
+		}
+	}
+}
+
+func extractSchemaType(crd *apiextensionsv1.CustomResourceDefinition, opts Options) (*pkg.SchemaType, error) {
+	if len(opts.Groups) > 0 && !slices.Contains(opts.Groups, crd.Spec.Group) {
+		return nil, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert CRD %s: %w", crd.Name, err)
+	}
+
+	schemaType, err := pkg.ExtractSchemaType(&unstructured.Unstructured{Object: content})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract schema type of %s: %w", crd.Name, err)
+	}
+
+	if schemaType != nil {
+		schemaType.Location = crd.Name
+	}
+
+	return schemaType, nil
+}
@@ -0,0 +1,75 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+// crdObjects decodes the CustomResourceDefinitions of a testdata file into objects for the fake clientset.
+func crdObjects(t *testing.T, name string) []runtime.Object {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	var objects []runtime.Object
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), pkg.DecoderBufferSize)
+	for {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := decoder.Decode(crd); err != nil {
+			require.ErrorIs(t, err, io.EOF)
+
+			return objects
+		}
+
+		objects = append(objects, crd)
+	}
+}
+
+func TestCRDs(t *testing.T) {
+	client := fake.NewClientset(crdObjects(t, "crds.yaml")...).ApiextensionsV1().CustomResourceDefinitions()
+
+	tests := []struct {
+		name  string
+		opts  Options
+		kinds []string
+	}{
+		{name: "all", kinds: []string{"Bar", "Baz", "Qux"}},
+		{name: "group", opts: Options{Groups: []string{"example.com"}}, kinds: []string{"Bar", "Baz"}},
+		{name: "label selector", opts: Options{LabelSelector: "app=bar"}, kinds: []string{"Bar", "Qux"}},
+		{name: "group and label selector", opts: Options{Groups: []string{"other.com"}, LabelSelector: "app=bar"}, kinds: []string{"Qux"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crds, err := CRDs(context.Background(), client, tt.opts)
+			require.NoError(t, err)
+
+			kinds := make([]string, 0, len(crds))
+			for _, c := range crds {
+				kinds = append(kinds, c.Kind)
+			}
+			assert.ElementsMatch(t, tt.kinds, kinds)
+		})
+	}
+
+	crds, err := CRDs(context.Background(), client, Options{Groups: []string{"other.com"}})
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+	assert.Equal(t, "quxes.other.com", crds[0].Location)
+	require.Len(t, crds[0].Versions, 1)
+	assert.Contains(t, crds[0].Versions[0].Schema.Properties["spec"].Properties, "replicas")
+}
@@ -0,0 +1,32 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// ToInternalSchema converts our copy of the JSON schema types into the internal apiextensions
+// representation that the apiserver's validation packages work with.
+func ToInternalSchema(props *v1beta1.JSONSchemaProps) (*apiextensions.JSONSchemaProps, error) {
+	content, err := json.Marshal(props)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	external := &apiextensionsv1.JSONSchemaProps{}
+	if err := json.Unmarshal(content, external); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+
+	internal := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(external, internal, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert schema: %w", err)
+	}
+
+	return internal, nil
+}
@@ -2,16 +2,21 @@ package pkg
 
 import (
 	"bytes"
+	"cmp"
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"slices"
 	"sort"
 	"strings"
 
+	"github.com/Masterminds/sprig/v3"
+
 	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
 )
 
@@ -27,53 +32,142 @@ type Version struct {
 	Properties  []*Property
 	Description string
 	YAML        string
+
+	PrinterColumns []PrinterColumn
+	Subresources   *Subresources
 }
 
-// ViewPage is the template for view.html.
+// ViewPage is a kind rendered by the HTML templates.
 type ViewPage struct {
 	Title    string
 	Versions []Version
+
+	Scope      string
+	Plural     string
+	ShortNames []string
+	Categories []string
+	Conversion string
 }
 
+// DefaultTemplate is the template HTML content is rendered with if no other template is selected.
+const DefaultTemplate = "view_with_groups.html"
+
 var (
 	//go:embed templates
 	files     embed.FS
 	templates map[string]*template.Template
+	// assets are the files of the assets folder by their path in it. The site contains them next to its pages.
+	assets map[string][]byte
 )
 
-// LoadTemplates creates a map of loaded templates that are primed and ready to be rendered.
-func LoadTemplates() error {
-	if templates == nil {
-		templates = make(map[string]*template.Template)
+// LoadTemplates creates a map of loaded templates that are primed and ready to be rendered. The
+// partials are parsed into every template, so they can be shared between pages. Templates, partials and
+// assets of the overrides replace the embedded ones of the same name and new ones are added. Next to the
+// functions of Sprig, templates can call stylesheet to inline assets/style.css.
+func LoadTemplates(overrides ...fs.FS) error {
+	embedded, err := fs.Sub(files, "templates")
+	if err != nil {
+		return err
+	}
+
+	pages := map[string]fs.FS{}
+	partials := map[string]fs.FS{}
+	loadedAssets := map[string][]byte{}
+	for _, fsys := range append([]fs.FS{embedded}, overrides...) {
+		if err := collectTemplates(fsys, "*.html", pages); err != nil {
+			return err
+		}
+
+		if err := collectTemplates(fsys, "partials/*.html", partials); err != nil {
+			return err
+		}
+
+		if err := collectAssets(fsys, loadedAssets); err != nil {
+			return err
+		}
 	}
-	tmplFiles, err := fs.ReadDir(files, "templates")
+
+	stylesheet := loadedAssets["style.css"]
+	funcs := sprig.HtmlFuncMap()
+	// templates may come from a third party, so they must not be able to write secrets of the
+	// environment, like tokens of the CI, into the output. Helm removes them for the same reason.
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+	// the stylesheet is provided by the binary or the user, so it's trusted to be embedded as is.
+	funcs["stylesheet"] = func() template.CSS { return template.CSS(stylesheet) } //nolint:gosec // see above
+
+	loaded := make(map[string]*template.Template, len(pages))
+	for name, fsys := range pages {
+		pt := template.New(name).Funcs(funcs)
+		// partials are parsed first, so a page can replace a partial with a definition of its own.
+		for _, partial := range slices.Sorted(maps.Keys(partials)) {
+			if pt, err = pt.ParseFS(partials[partial], partial); err != nil {
+				return fmt.Errorf("failed to parse partial %s: %w", partial, err)
+			}
+		}
+
+		if pt, err = pt.ParseFS(fsys, name); err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", name, err)
+		}
+
+		loaded[name] = pt
+	}
+
+	templates = loaded
+	assets = loadedAssets
+
+	return nil
+}
+
+// collectTemplates sets the source of the templates matching pattern in fsys.
+func collectTemplates(fsys fs.FS, pattern string, sources map[string]fs.FS) error {
+	names, err := fs.Glob(fsys, pattern)
 	if err != nil {
 		return err
 	}
 
-	for _, tmpl := range tmplFiles {
-		if tmpl.IsDir() {
-			continue
+	for _, name := range names {
+		sources[name] = fsys
+	}
+
+	return nil
+}
+
+// collectAssets reads the files of the assets folder of fsys if it has one.
+func collectAssets(fsys fs.FS, assets map[string][]byte) error {
+	err := fs.WalkDir(fsys, "assets", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
 		}
-		pt, err := template.ParseFS(files, "templates/"+tmpl.Name())
+
+		content, err := fs.ReadFile(fsys, name)
 		if err != nil {
 			return err
 		}
 
-		templates[tmpl.Name()] = pt
+		assets[strings.TrimPrefix(name, "assets/")] = content
+
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
 	}
 
-	return nil
+	return err
 }
 
 // Group defines a single group with a list of rendered versions.
 type Group struct {
-	Name string
-	Page []ViewPage
+	Name        string
+	Description string
+	Logo        string
+	Link        string
+	Page        []ViewPage
 }
 
 // GroupPage will have a list of groups and inside these groups
-// will be a list of page views.
+// will be a list of page views. It's the data of the HTML templates, which is documented in
+// templates-README.md for custom templates, so fields must not be renamed or removed.
 type GroupPage struct {
 	Groups []Group
 }
@@ -82,6 +176,8 @@ type RenderOpts struct {
 	Comments bool
 	Minimal  bool
 	Random   bool
+	// Template is the name of the template RenderContent renders with. Defaults to DefaultTemplate.
+	Template string
 }
 
 // RenderContent creates an HTML website from the CRD content.
@@ -94,50 +190,35 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 
 	groups := buildUpGroup(crds)
 
-	allGroups := make([]Group, 0)
-	for name, group := range groups {
-		allViews := make([]ViewPage, 0, len(group))
-
-		for _, crd := range group {
-			versions := make([]Version, 0)
-			parser := NewParser(crd.Group, crd.Kind, opts.Comments, opts.Minimal, opts.Random)
-
-			for _, version := range crd.Versions {
-				v, err := generate(version.Name, crd.Group, crd.Kind, version.Schema, opts.Minimal, parser)
-				if err != nil {
-					return fmt.Errorf("failed to generate yaml sample: %w", err)
-				}
-
-				versions = append(versions, v)
-			}
-
-			// parse validation instead
-			if len(versions) == 0 && crd.Validation != nil {
-				version, err := generate(crd.Validation.Name, crd.Group, crd.Kind, crd.Validation.Schema, opts.Minimal, parser)
-				if err != nil {
-					return fmt.Errorf("failed to generate yaml sample: %w", err)
-				}
+	allGroups := make([]Group, 0, len(groups))
+	for _, group := range groups {
+		allViews := make([]ViewPage, 0, len(group.crds))
 
-				versions = append(versions, version)
-			} else if len(versions) == 0 {
-				continue
+		for _, crd := range group.crds {
+			view, ok, err := viewPage(crd, opts)
+			if err != nil {
+				return err
 			}
 
-			view := ViewPage{
-				Title:    crd.Kind,
-				Versions: versions,
+			if ok {
+				allViews = append(allViews, view)
 			}
-
-			allViews = append(allViews, view)
 		}
 
 		allGroups = append(allGroups, Group{
-			Name: name,
-			Page: allViews,
+			Name:        group.name,
+			Description: group.description,
+			Logo:        group.logo,
+			Link:        group.link,
+			Page:        allViews,
 		})
 	}
 
-	t := templates["view_with_groups.html"]
+	name := cmp.Or(opts.Template, DefaultTemplate)
+	t, ok := templates[name]
+	if !ok {
+		return fmt.Errorf("template %s is not loaded", name)
+	}
 
 	index := GroupPage{
 		Groups: allGroups,
@@ -150,17 +231,92 @@ func RenderContent(w io.WriteCloser, crds []*SchemaType, opts RenderOpts) (err e
 	return nil
 }
 
-func buildUpGroup(crds []*SchemaType) map[string][]*SchemaType {
-	result := map[string][]*SchemaType{}
+// viewPage renders the versions of the CRD. It returns false if the CRD has nothing to render.
+func viewPage(crd *SchemaType, opts RenderOpts) (ViewPage, bool, error) {
+	if err := crd.ResolveRefs(); err != nil {
+		return ViewPage{}, false, fmt.Errorf("failed to resolve references of %s: %w", crd.Kind, err)
+	}
+
+	crdOpts := crd.Rendering.Options(opts)
+	versions := make([]Version, 0)
+	parser := NewParser(crd.Group, crd.Kind, crdOpts.Comments, crdOpts.Minimal, crdOpts.Random)
+
+	for _, version := range crd.Versions {
+		v, err := generate(version.Name, crd.Group, crd.Kind, version.Schema, crdOpts.Minimal, parser)
+		if err != nil {
+			return ViewPage{}, false, fmt.Errorf("failed to generate yaml sample: %w", err)
+		}
+
+		v.PrinterColumns = version.PrinterColumns
+		v.Subresources = version.Subresources
+		versions = append(versions, v)
+	}
+
+	// parse validation instead
+	if len(versions) == 0 && crd.Validation != nil {
+		version, err := generate(crd.Validation.VersionName(), crd.Group, crd.Kind, crd.Validation.Schema, crdOpts.Minimal, parser)
+		if err != nil {
+			return ViewPage{}, false, fmt.Errorf("failed to generate yaml sample: %w", err)
+		}
+
+		version.PrinterColumns = crd.Validation.PrinterColumns
+		version.Subresources = crd.Validation.Subresources
+
+		versions = append(versions, version)
+	} else if len(versions) == 0 {
+		return ViewPage{}, false, nil
+	}
+
+	return ViewPage{
+		Title:      crd.Kind,
+		Versions:   versions,
+		Scope:      crd.Scope,
+		Plural:     crd.Plural,
+		ShortNames: crd.ShortNames,
+		Categories: crd.Categories,
+		Conversion: crd.Conversion,
+	}, true, nil
+}
+
+// crdGroup is a group of CRDs and the metadata of the group.
+type crdGroup struct {
+	name        string
+	description string
+	logo        string
+	link        string
+	order       int
+	crds        []*SchemaType
+}
+
+// buildUpGroup groups the CRDs by their rendering group. The groups are ordered by their order and
+// then by the order they are first seen in, so the output doesn't change between runs. The metadata of
+// a group is taken from the first of its CRDs that sets it.
+func buildUpGroup(crds []*SchemaType) []*crdGroup {
+	var groups []*crdGroup
+	byName := map[string]*crdGroup{}
 	for _, crd := range crds {
 		if crd.Rendering.Group == "" {
 			crd.Rendering.Group = crd.Group
 		}
 
-		result[crd.Rendering.Group] = append(result[crd.Rendering.Group], crd)
+		group, ok := byName[crd.Rendering.Group]
+		if !ok {
+			group = &crdGroup{name: crd.Rendering.Group, order: crd.Rendering.Order}
+			byName[group.name] = group
+			groups = append(groups, group)
+		}
+
+		group.description = cmp.Or(group.description, crd.Rendering.Description)
+		group.logo = cmp.Or(group.logo, crd.Rendering.Logo)
+		group.link = cmp.Or(group.link, crd.Rendering.Link)
+		group.crds = append(group.crds, crd)
 	}
 
-	return result
+	slices.SortStableFunc(groups, func(a, b *crdGroup) int {
+		return cmp.Compare(a.order, b.order)
+	})
+
+	return groups
 }
 
 func generate(name, group, kind string, properties *v1beta1.JSONSchemaProps, minimal bool, parser *Parser) (Version, error) {
@@ -168,6 +324,7 @@ func generate(name, group, kind string, properties *v1beta1.JSONSchemaProps, min
 	if err != nil {
 		return Version{}, fmt.Errorf("failed to parse properties: %w", err)
 	}
+	setPaths(out, "", anchorPrefix(group, kind, name))
 	var buffer []byte
 	buf := bytes.NewBuffer(buffer)
 	if err := parser.ParseProperties(name, buf, properties.Properties, RootRequiredFields); err != nil {
@@ -198,6 +355,32 @@ type Property struct {
 	Required    bool
 	Properties  []*Property
 	Enums       string
+	// Path is the path of the property from the root of the object, like spec.template.metadata.
+	Path string
+	// Anchor is the id of the property in a page. It's the path prefixed with the group, kind and
+	// version, so it's unique on pages showing several kinds and versions.
+	Anchor string
+}
+
+// anchorPrefix returns the prefix of the anchors of the properties of a version of a kind.
+func anchorPrefix(group, kind, version string) string {
+	return strings.Join(slices.DeleteFunc([]string{group, strings.ToLower(kind), version}, func(s string) bool {
+		return s == ""
+	}), "-")
+}
+
+// setPaths sets the path and anchor of the properties and the properties embedded in them.
+func setPaths(properties []*Property, parent, prefix string) {
+	for _, p := range properties {
+		p.Path = p.Name
+		if parent != "" {
+			p.Path = parent + "." + p.Name
+		}
+
+		p.Anchor = prefix + "-" + p.Path
+
+		setPaths(p.Properties, p.Path, prefix)
+	}
 }
 
 // parseCRD takes the properties and constructs a linked list out of the embedded properties that the recursive
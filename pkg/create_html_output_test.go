@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestBuildUpGroup(t *testing.T) {
+	crds := []*SchemaType{
+		{Kind: "Cluster", Group: "infrastructure.cluster.x-k8s.io"},
+		{Kind: "Bucket", Rendering: Rendering{Group: "storage", Order: 1}},
+		{Kind: "Machine", Group: "infrastructure.cluster.x-k8s.io"},
+		{Kind: "Volume", Rendering: Rendering{Group: "storage", Description: "Storage resources", Link: "https://example.com/storage", Order: 1}},
+		{Kind: "Deployment", Rendering: Rendering{Group: "apps", Logo: "https://example.com/logo.png", Order: -1}},
+	}
+
+	groups := buildUpGroup(crds)
+
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.name)
+	}
+
+	assert.Equal(t, []string{"apps", "infrastructure.cluster.x-k8s.io", "storage"}, names)
+	assert.Equal(t, "https://example.com/logo.png", groups[0].logo)
+	assert.Len(t, groups[1].crds, 2)
+	assert.Equal(t, "Storage resources", groups[2].description)
+	assert.Equal(t, "https://example.com/storage", groups[2].link)
+	assert.Equal(t, "Bucket", groups[2].crds[0].Kind)
+}
+
+func TestRenderContentWithGroups(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	content, err := os.ReadFile(filepath.Join("testdata", "sample_crd.yaml"))
+	require.NoError(t, err)
+
+	var crds []*SchemaType
+	for _, group := range []string{"second", "first"} {
+		crd := &unstructured.Unstructured{}
+		require.NoError(t, yaml.Unmarshal(content, crd))
+		schemaType, err := ExtractSchemaType(crd)
+		require.NoError(t, err)
+
+		schemaType.Rendering = Rendering{Group: group, Description: "The " + group + " group", Link: "https://example.com/" + group}
+		if group == "first" {
+			schemaType.Rendering.Order = -1
+		}
+
+		crds = append(crds, schemaType)
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderContent(&WriteNoOpCloser{w: buffer}, crds, RenderOpts{Random: true}))
+
+	output := buffer.String()
+	assert.Contains(t, output, `<a href="https://example.com/first">first</a>`)
+	assert.Contains(t, output, `<p class="text-muted">The second group</p>`)
+	assert.Less(t, strings.Index(output, "The first group"), strings.Index(output, "The second group"))
+}
+
+func TestRenderContentWithUniqueAnchors(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	var crds []*SchemaType
+	for _, file := range []string{"sample_crd.yaml", "sample_crd_with_list_and_multiple_versions.yaml"} {
+		content, err := os.ReadFile(filepath.Join("testdata", file))
+		require.NoError(t, err)
+
+		crd := &unstructured.Unstructured{}
+		require.NoError(t, yaml.Unmarshal(content, crd))
+		schemaType, err := ExtractSchemaType(crd)
+		require.NoError(t, err)
+
+		crds = append(crds, schemaType)
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderContent(&WriteNoOpCloser{w: buffer}, crds, RenderOpts{Random: true}))
+
+	// every kind and version is on the same page, so their properties need their own ids.
+	ids := map[string]int{}
+	for _, match := range regexp.MustCompile(`id="([^"]+)" class="collapse-content"`).FindAllStringSubmatch(buffer.String(), -1) {
+		ids[match[1]]++
+	}
+
+	assert.Equal(t, 1, ids["infrastructure.cluster.x-k8s.io-awscluster-v1beta1-spec"])
+	assert.Equal(t, 1, ids["infrastructure.cluster.x-k8s.io-awscluster-v1beta2-spec"])
+	assert.Equal(t, 1, ids["delivery.krok.app-krokcommand-v1alpha1-spec"])
+	for id, count := range ids {
+		assert.Equal(t, 1, count, id)
+	}
+}
+
+func TestLoadTemplatesWithOverrides(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, LoadTemplates())
+	})
+
+	overrides := fstest.MapFS{
+		"view_with_groups.html": {Data: []byte(`{{range .Groups}}<h1>{{.Name | upper}}</h1>{{range .Page}}{{template "title" .}}{{end}}{{end}}`)},
+		"custom.html":           {Data: []byte(`<style>{{stylesheet}}</style>{{len .Groups}} groups`)},
+		"partials/title.html":   {Data: []byte(`{{define "title"}}<h2>{{.Title | lower}}</h2>{{end}}`)},
+		"assets/style.css":      {Data: []byte(`body { color: red; }`)},
+		"assets/logo.svg":       {Data: []byte(`<svg></svg>`)},
+	}
+	require.NoError(t, LoadTemplates(overrides))
+
+	content, err := os.ReadFile(filepath.Join("testdata", "sample_crd.yaml"))
+	require.NoError(t, err)
+
+	crd := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal(content, crd))
+	schemaType, err := ExtractSchemaType(crd)
+	require.NoError(t, err)
+	schemaType.Rendering = Rendering{Group: "krok"}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, RenderContent(&WriteNoOpCloser{w: buffer}, []*SchemaType{schemaType}, RenderOpts{}))
+	assert.Equal(t, "<h1>KROK</h1><h2>krokcommand</h2>", buffer.String())
+
+	buffer.Reset()
+	require.NoError(t, RenderContent(&WriteNoOpCloser{w: buffer}, []*SchemaType{schemaType}, RenderOpts{Template: "custom.html"}))
+	assert.Equal(t, "<style>body { color: red; }</style>1 groups", buffer.String())
+
+	// the built-in templates that aren't replaced are still available.
+	dir := t.TempDir()
+	require.NoError(t, RenderSite(dir, []*SchemaType{schemaType}, RenderOpts{}, ""))
+	assert.FileExists(t, filepath.Join(dir, "index.html"))
+	logo, err := os.ReadFile(filepath.Join(dir, "assets", "logo.svg"))
+	require.NoError(t, err)
+	assert.Equal(t, "<svg></svg>", string(logo))
+
+	err = RenderContent(&WriteNoOpCloser{w: buffer}, nil, RenderOpts{Template: "missing.html"})
+	assert.EqualError(t, err, "template missing.html is not loaded")
+}
+
+func TestLoadTemplatesWithInvalidOverride(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, LoadTemplates())
+	})
+
+	err := LoadTemplates(fstest.MapFS{"broken.html": {Data: []byte(`{{if}}`)}})
+	assert.ErrorContains(t, err, "failed to parse template broken.html")
+
+	// the environment isn't available to templates.
+	for _, function := range []string{"env", "expandenv"} {
+		err = LoadTemplates(fstest.MapFS{"secret.html": {Data: []byte(`{{` + function + ` "GITHUB_TOKEN"}}`)}})
+		assert.ErrorContains(t, err, `function "`+function+`" not defined`)
+	}
+}
@@ -0,0 +1,242 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// the site is meant to be served, so its files are readable by everyone.
+const (
+	siteDirPerm  = 0o755
+	siteFilePerm = 0o644
+)
+
+// SitePage is the template for the pages of site_index.html and site_page.html.
+type SitePage struct {
+	Title string
+	// Root is the path of the root of the site relative to the page, so the site works under any path.
+	Root string
+	// Path is the path of the page relative to the root of the site.
+	Path   string
+	Groups []SiteGroup
+	// Kind and Version are the kind and version the page documents. They are nil for the index.
+	Kind    *SiteKind
+	Version *Version
+}
+
+// SiteGroup is a group of kinds of the site.
+type SiteGroup struct {
+	Name        string
+	Description string
+	Logo        string
+	Link        string
+	Kinds       []*SiteKind
+}
+
+// SiteKind is a kind of the site with the pages of its versions.
+type SiteKind struct {
+	ViewPage
+	Group string
+	Pages []SiteLink
+}
+
+// SiteLink is a link to a page of the site.
+type SiteLink struct {
+	Name string
+	Path string
+}
+
+// RenderSite renders the CRDs into a static site in dir. The site has an index of the groups and their
+// kinds and a page for every version of a kind under group/version/kind.html, so links to a page don't
+// change between runs. The loaded assets are copied into the assets folder of the site. If siteURL is
+// set, a sitemap of the pages is written with siteURL as their base.
+func RenderSite(dir string, crds []*SchemaType, opts RenderOpts, siteURL string) error {
+	var (
+		groups []SiteGroup
+		pages  []SitePage
+	)
+
+	seen := map[string]bool{}
+	for _, group := range buildUpGroup(crds) {
+		siteGroup := SiteGroup{
+			Name:        group.name,
+			Description: group.description,
+			Logo:        group.logo,
+			Link:        group.link,
+		}
+
+		// CRDs of the same kind with different versions are listed as one kind with the pages of all versions.
+		kinds := map[string]*SiteKind{}
+		for _, crd := range group.crds {
+			view, ok, err := viewPage(crd, opts)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				continue
+			}
+
+			key := crd.Group + "/" + crd.Kind
+			kind, ok := kinds[key]
+			if !ok {
+				kind = &SiteKind{ViewPage: view, Group: crd.Group}
+				kinds[key] = kind
+			}
+
+			for i := range view.Versions {
+				version := &view.Versions[i]
+				name := version.Version
+				pagePath := sitePagePath(crd.Group, name, crd.Kind)
+				// the first CRD defining a kind wins, the same way the API server would refuse the second one.
+				if seen[pagePath] {
+					continue
+				}
+
+				seen[pagePath] = true
+				kind.Pages = append(kind.Pages, SiteLink{Name: name, Path: pagePath})
+				pages = append(pages, SitePage{
+					Title:   crd.Kind + " " + name,
+					Root:    strings.Repeat("../", strings.Count(pagePath, "/")),
+					Path:    pagePath,
+					Kind:    kind,
+					Version: version,
+				})
+			}
+
+			if len(kind.Pages) > 0 && !slices.Contains(siteGroup.Kinds, kind) {
+				siteGroup.Kinds = append(siteGroup.Kinds, kind)
+			}
+		}
+
+		groups = append(groups, siteGroup)
+	}
+
+	pages = append([]SitePage{{Title: "CRDs", Path: "index.html"}}, pages...)
+	for _, page := range pages {
+		page.Groups = groups
+
+		name := "site_page.html"
+		if page.Kind == nil {
+			name = "site_index.html"
+		}
+
+		if err := renderSitePage(dir, name, page); err != nil {
+			return err
+		}
+	}
+
+	for name, content := range assets {
+		if err := writeSiteFile(dir, path.Join("assets", name), content); err != nil {
+			return err
+		}
+	}
+
+	// GitHub Pages processes sites with Jekyll unless they contain a .nojekyll file.
+	if err := writeSiteFile(dir, ".nojekyll", nil); err != nil {
+		return err
+	}
+
+	if siteURL == "" {
+		return nil
+	}
+
+	sitemap, err := siteMap(siteURL, pages)
+	if err != nil {
+		return err
+	}
+
+	return writeSiteFile(dir, "sitemap.xml", sitemap)
+}
+
+// sitePagePath returns the path of the page of a version of a kind. The core group of built-in kinds is empty.
+func sitePagePath(group, version, kind string) string {
+	if group == "" {
+		group = "core"
+	}
+
+	return path.Join(slug(group), slug(version), slug(strings.ToLower(kind))+".html")
+}
+
+// slug replaces everything but letters, digits, dots and dashes in s, so it can be used in a path.
+func slug(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+
+	// dots alone would point to the parent folder.
+	if strings.Trim(s, ".") == "" {
+		return strings.Repeat("-", len(s)) + "_"
+	}
+
+	return s
+}
+
+func renderSitePage(dir, name string, page SitePage) error {
+	t, ok := templates[name]
+	if !ok {
+		return fmt.Errorf("template %s is not loaded", name)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, page); err != nil {
+		return fmt.Errorf("failed to execute template for %s: %w", page.Path, err)
+	}
+
+	return writeSiteFile(dir, page.Path, buf.Bytes())
+}
+
+func writeSiteFile(dir, name string, content []byte) error {
+	location := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(location), siteDirPerm); err != nil {
+		return fmt.Errorf("failed to create folder for %s: %w", name, err)
+	}
+
+	if err := os.WriteFile(location, content, siteFilePerm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// siteMap returns a sitemap of the pages with siteURL as their base.
+func siteMap(siteURL string, pages []SitePage) ([]byte, error) {
+	base := strings.TrimSuffix(siteURL, "/") + "/"
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, page := range pages {
+		loc := base + page.Path
+		if page.Path == "index.html" {
+			loc = base
+		}
+
+		set.URLs = append(set.URLs, sitemapURL{Loc: loc})
+	}
+
+	content, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sitemap: %w", err)
+	}
+
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
@@ -0,0 +1,160 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestRenderSite(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	var crds []*SchemaType
+	for _, file := range []string{"sample_crd.yaml", "sample_crd_with_list_and_multiple_versions.yaml"} {
+		content, err := os.ReadFile(filepath.Join("testdata", file))
+		require.NoError(t, err)
+
+		crd := &unstructured.Unstructured{}
+		require.NoError(t, yaml.Unmarshal(content, crd))
+		schemaType, err := ExtractSchemaType(crd)
+		require.NoError(t, err)
+
+		crds = append(crds, schemaType)
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, RenderSite(dir, crds, RenderOpts{Random: true}, "https://example.github.io/crds/"))
+
+	for _, name := range []string{"index.html", "assets/style.css", ".nojekyll", "sitemap.xml"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+
+	var pages []string
+	for _, crd := range crds {
+		for _, version := range crd.Versions {
+			page := sitePagePath(crd.Group, version.Name, crd.Kind)
+			assert.FileExists(t, filepath.Join(dir, page))
+			pages = append(pages, page)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	for _, page := range pages {
+		assert.Contains(t, string(index), `href="`+page+`"`)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, pages[0]))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `href="../../assets/style.css"`)
+	assert.Contains(t, string(page), `href="../../`+pages[0]+`" class="sidebar-link active"`)
+	assert.Contains(t, string(page), `id="delivery.krok.app-krokcommand-v1alpha1-spec.readInputFromSecret.name"`)
+	assert.Contains(t, string(page), `href="#delivery.krok.app-krokcommand-v1alpha1-spec.readInputFromSecret.name"`)
+
+	sitemap, err := os.ReadFile(filepath.Join(dir, "sitemap.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(sitemap), "<loc>https://example.github.io/crds/</loc>")
+	assert.Contains(t, string(sitemap), "<loc>https://example.github.io/crds/"+pages[0]+"</loc>")
+}
+
+func TestRenderSiteWithValidation(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	tests := []struct {
+		name          string
+		removeVersion bool
+		version       string
+		page          string
+	}{
+		{name: "with version", version: "v1", page: "monitoring.coreos.com/v1/prometheus.html"},
+		{name: "without version", removeVersion: true, version: DefaultValidationVersion, page: "monitoring.coreos.com/v1beta1/prometheus.html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("testdata", "sample_crd_with_validation.yaml"))
+			require.NoError(t, err)
+
+			crd := &unstructured.Unstructured{}
+			require.NoError(t, yaml.Unmarshal(content, crd))
+			if tt.removeVersion {
+				unstructured.RemoveNestedField(crd.Object, "spec", "version")
+			}
+
+			schemaType, err := ExtractSchemaType(crd)
+			require.NoError(t, err)
+
+			dir := t.TempDir()
+			require.NoError(t, RenderSite(dir, []*SchemaType{schemaType}, RenderOpts{}, ""))
+			assert.FileExists(t, filepath.Join(dir, tt.page))
+			assert.NoDirExists(t, filepath.Join(dir, "monitoring.coreos.com", "prometheuses.monitoring.coreos.com"))
+
+			index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+			require.NoError(t, err)
+			assert.Contains(t, string(index), `href="`+tt.page+`"`)
+
+			// the heading and the anchors of the page name the same version as its path.
+			page, err := os.ReadFile(filepath.Join(dir, tt.page))
+			require.NoError(t, err)
+			assert.Contains(t, string(page), `id="monitoring.coreos.com-prometheus-`+tt.version+`-spec`)
+			assert.NotContains(t, string(page), "prometheuses.monitoring.coreos.com")
+		})
+	}
+}
+
+func TestRenderSiteMergesVersionsOfAKind(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	content, err := os.ReadFile(filepath.Join("testdata", "sample_crd.yaml"))
+	require.NoError(t, err)
+
+	var crds []*SchemaType
+	for _, version := range []string{"v1alpha1", "v1beta1"} {
+		crd := &unstructured.Unstructured{}
+		require.NoError(t, yaml.Unmarshal(content, crd))
+
+		versions, _, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		require.NoError(t, err)
+		require.NoError(t, unstructured.SetNestedField(versions[0].(map[string]any), version, "name"))
+		require.NoError(t, unstructured.SetNestedSlice(crd.Object, versions, "spec", "versions"))
+
+		schemaType, err := ExtractSchemaType(crd)
+		require.NoError(t, err)
+
+		crds = append(crds, schemaType)
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, RenderSite(dir, crds, RenderOpts{}, ""))
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(index), ">KrokCommand</a></td>"))
+	assert.Contains(t, string(index), `href="delivery.krok.app/v1beta1/krokcommand.html"`)
+
+	page, err := os.ReadFile(filepath.Join(dir, "delivery.krok.app", "v1alpha1", "krokcommand.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `href="../../delivery.krok.app/v1beta1/krokcommand.html"><kbd`)
+}
+
+func TestRenderSiteWithoutSiteURL(t *testing.T) {
+	require.NoError(t, LoadTemplates())
+
+	dir := t.TempDir()
+	require.NoError(t, RenderSite(dir, nil, RenderOpts{}, ""))
+
+	assert.FileExists(t, filepath.Join(dir, "index.html"))
+	assert.NoFileExists(t, filepath.Join(dir, "sitemap.xml"))
+}
+
+func TestSitePagePath(t *testing.T) {
+	assert.Equal(t, "delivery.krok.app/v1alpha1/krokcommand.html", sitePagePath("delivery.krok.app", "v1alpha1", "KrokCommand"))
+	assert.Equal(t, "core/v1/pod.html", sitePagePath("", "v1", "Pod"))
+	assert.Equal(t, "--_/v1/a-b.html", sitePagePath("..", "v1", "a/b"))
+}
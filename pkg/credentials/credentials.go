@@ -0,0 +1,114 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	redacted = "***"
+	// helperTimeout limits how long a git credential helper may take.
+	helperTimeout = 30 * time.Second
+)
+
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnv replaces references like ${GITHUB_TOKEN} with the value of the environment variable. It fails
+// if a referenced variable isn't set, so a missing secret isn't silently replaced by an empty string.
+func ExpandEnv(s string) (string, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllStringFunc(s, func(reference string) string {
+		name := envReference.FindStringSubmatch(reference)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable(s) %s not set", strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
+
+// GitHelper asks the git credential helpers configured for rawURL, like the ones storing the credentials
+// of git clone, for a username and password. Helpers aren't allowed to prompt for credentials.
+func GitHelper(rawURL string) (username, password string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), helperTimeout)
+	defer cancel()
+
+	input := fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/"))
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(input)
+	// Git Credential Manager ignores GIT_TERMINAL_PROMPT and opens a window unless GCM_INTERACTIVE is set.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=", "GCM_INTERACTIVE=never")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		case "username":
+			username = value
+		case "password":
+			password = value
+		}
+	}
+
+	return username, password, password != ""
+}
+
+// redactedError hides secrets in the message of the error it wraps.
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Redact returns an error whose message doesn't contain any of the secrets. The original error can
+// still be found with errors.Is and errors.As.
+func Redact(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	message := err.Error()
+	for _, secret := range secrets {
+		if secret != "" {
+			message = strings.ReplaceAll(message, secret, redacted)
+		}
+	}
+
+	if message == err.Error() {
+		return err
+	}
+
+	return &redactedError{err: err, message: message}
+}
@@ -0,0 +1,110 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Netrc returns the login and password of host in the netrc file at $NETRC or ~/.netrc. Machines are
+// matched with and without the port of host. A default entry is used if no machine matches.
+func Netrc(host string) (username, password string, ok bool, err error) {
+	location := os.Getenv("NETRC")
+	if location == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", false, nil
+		}
+
+		location = filepath.Join(home, ".netrc")
+	}
+
+	content, err := os.ReadFile(location)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", false, nil
+		}
+
+		return "", "", false, fmt.Errorf("failed to read netrc file: %w", err)
+	}
+
+	hostname, _, _ := strings.Cut(host, ":")
+	entries := parseNetrc(content)
+	for _, candidate := range []string{host, hostname, ""} {
+		for _, e := range entries {
+			if e.machine == candidate {
+				return e.login, e.password, true, nil
+			}
+		}
+	}
+
+	return "", "", false, nil
+}
+
+// netrcEntry is a machine of a netrc file. The default entry has an empty machine.
+type netrcEntry struct {
+	machine  string
+	login    string
+	password string
+}
+
+func parseNetrc(content []byte) []netrcEntry {
+	var (
+		entries []netrcEntry
+		current *netrcEntry
+		tokens  []string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		// macro definitions end at an empty line.
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if i := slices.Index(fields, "macdef"); i != -1 {
+			fields = fields[:i]
+			inMacro = true
+		}
+
+		tokens = append(tokens, fields...)
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine":
+			if i+1 < len(tokens) {
+				i++
+				entries = append(entries, netrcEntry{machine: tokens[i]})
+				current = &entries[len(entries)-1]
+			}
+		case "default":
+			entries = append(entries, netrcEntry{})
+			current = &entries[len(entries)-1]
+		case "login", "password", "account":
+			if i+1 >= len(tokens) || current == nil {
+				continue
+			}
+
+			i++
+			switch tokens[i-1] {
+			case "login":
+				current.login = tokens[i]
+			case "password":
+				current.password = tokens[i]
+			}
+		}
+	}
+
+	return entries
+}
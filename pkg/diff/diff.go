@@ -0,0 +1,354 @@
+package diff
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// ChangeType describes what kind of change happened to a CRD.
+type ChangeType string
+
+const (
+	CRDAdded        ChangeType = "CRDAdded"
+	CRDRemoved      ChangeType = "CRDRemoved"
+	VersionAdded    ChangeType = "VersionAdded"
+	VersionRemoved  ChangeType = "VersionRemoved"
+	FieldAdded      ChangeType = "FieldAdded"
+	FieldRemoved    ChangeType = "FieldRemoved"
+	TypeChanged     ChangeType = "TypeChanged"
+	RequiredAdded   ChangeType = "RequiredAdded"
+	RequiredRemoved ChangeType = "RequiredRemoved"
+	EnumChanged     ChangeType = "EnumChanged"
+	PatternChanged  ChangeType = "PatternChanged"
+	BoundsChanged   ChangeType = "BoundsChanged"
+)
+
+// Change is a single difference between two CRDs.
+type Change struct {
+	Group    string     `json:"group"`
+	Kind     string     `json:"kind"`
+	Version  string     `json:"version,omitempty"`
+	Path     string     `json:"path,omitempty"`
+	Type     ChangeType `json:"type"`
+	Breaking bool       `json:"breaking"`
+	Message  string     `json:"message"`
+}
+
+// Report contains all the changes found between two sets of CRDs.
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking returns the number of breaking changes in the report.
+func (r *Report) Breaking() int {
+	count := 0
+	for _, c := range r.Changes {
+		if c.Breaking {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Compare matches the old and new CRDs by group and kind and reports all changes between them.
+func Compare(oldCRDs, newCRDs []*pkg.SchemaType) *Report {
+	report := &Report{}
+
+	newByKey := make(map[string]*pkg.SchemaType, len(newCRDs))
+	for _, crd := range newCRDs {
+		newByKey[key(crd)] = crd
+	}
+
+	oldByKey := make(map[string]*pkg.SchemaType, len(oldCRDs))
+	for _, crd := range oldCRDs {
+		oldByKey[key(crd)] = crd
+
+		newCRD, ok := newByKey[key(crd)]
+		if !ok {
+			report.Changes = append(report.Changes, Change{
+				Group:    crd.Group,
+				Kind:     crd.Kind,
+				Type:     CRDRemoved,
+				Breaking: true,
+				Message:  "CRD was removed",
+			})
+
+			continue
+		}
+
+		report.Changes = append(report.Changes, CompareCRD(crd, newCRD)...)
+	}
+
+	for _, crd := range newCRDs {
+		if _, ok := oldByKey[key(crd)]; !ok {
+			report.Changes = append(report.Changes, Change{
+				Group:   crd.Group,
+				Kind:    crd.Kind,
+				Type:    CRDAdded,
+				Message: "CRD was added",
+			})
+		}
+	}
+
+	return report
+}
+
+// CompareCRD compares every version of two CRDs with the same group and kind.
+func CompareCRD(oldCRD, newCRD *pkg.SchemaType) []Change {
+	oldVersions := versions(oldCRD)
+	newVersions := versions(newCRD)
+
+	c := &comparer{group: oldCRD.Group, kind: oldCRD.Kind}
+
+	for _, name := range slices.Sorted(maps.Keys(oldVersions)) {
+		newSchema, ok := newVersions[name]
+		if !ok {
+			c.version = name
+			c.add("", VersionRemoved, true, "version was removed")
+
+			continue
+		}
+
+		c.version = name
+		c.compare("", oldVersions[name], newSchema)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(newVersions)) {
+		if _, ok := oldVersions[name]; !ok {
+			c.version = name
+			c.add("", VersionAdded, false, "version was added")
+		}
+	}
+
+	return c.changes
+}
+
+type comparer struct {
+	group   string
+	kind    string
+	version string
+	changes []Change
+}
+
+func (c *comparer) add(path string, t ChangeType, breaking bool, msg string) {
+	c.changes = append(c.changes, Change{
+		Group:    c.group,
+		Kind:     c.kind,
+		Version:  c.version,
+		Path:     path,
+		Type:     t,
+		Breaking: breaking,
+		Message:  msg,
+	})
+}
+
+// compare walks both schemas and records differences in the given path.
+func (c *comparer) compare(path string, oldSchema, newSchema *v1beta1.JSONSchemaProps) {
+	if oldSchema == nil || newSchema == nil {
+		return
+	}
+
+	if oldSchema.Type != newSchema.Type {
+		c.add(path, TypeChanged, true, fmt.Sprintf("type changed from %q to %q", oldSchema.Type, newSchema.Type))
+
+		// there is no point in comparing the rest of a field that changed its type.
+		return
+	}
+
+	c.compareEnum(path, oldSchema, newSchema)
+	c.comparePattern(path, oldSchema, newSchema)
+	c.compareBounds(path, oldSchema, newSchema)
+	c.compareRequired(path, oldSchema, newSchema)
+
+	for _, name := range slices.Sorted(maps.Keys(oldSchema.Properties)) {
+		oldProp := oldSchema.Properties[name]
+		fieldPath := path + "." + name
+
+		newProp, ok := newSchema.Properties[name]
+		if !ok {
+			c.add(fieldPath, FieldRemoved, true, "field was removed")
+
+			continue
+		}
+
+		c.compare(fieldPath, &oldProp, &newProp)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(newSchema.Properties)) {
+		if _, ok := oldSchema.Properties[name]; ok {
+			continue
+		}
+
+		// a new required field is additionally reported as breaking by compareRequired.
+		c.add(path+"."+name, FieldAdded, false, "field was added")
+	}
+
+	if oldSchema.Items != nil && newSchema.Items != nil {
+		c.compare(path+"[*]", oldSchema.Items.Schema, newSchema.Items.Schema)
+	}
+
+	if oldSchema.AdditionalProperties != nil && newSchema.AdditionalProperties != nil {
+		c.compare(path+".*", oldSchema.AdditionalProperties.Schema, newSchema.AdditionalProperties.Schema)
+	}
+}
+
+func (c *comparer) compareRequired(path string, oldSchema, newSchema *v1beta1.JSONSchemaProps) {
+	for _, r := range newSchema.Required {
+		if !slices.Contains(oldSchema.Required, r) {
+			c.add(path+"."+r, RequiredAdded, true, "field became required")
+		}
+	}
+
+	for _, r := range oldSchema.Required {
+		if !slices.Contains(newSchema.Required, r) {
+			c.add(path+"."+r, RequiredRemoved, false, "field is no longer required")
+		}
+	}
+}
+
+func (c *comparer) compareEnum(path string, oldSchema, newSchema *v1beta1.JSONSchemaProps) {
+	oldValues := enumValues(oldSchema.Enum)
+	newValues := enumValues(newSchema.Enum)
+
+	switch {
+	case len(oldValues) == 0 && len(newValues) == 0:
+		return
+	case len(oldValues) == 0:
+		c.add(path, EnumChanged, true, fmt.Sprintf("enum %v was added", newValues))
+
+		return
+	case len(newValues) == 0:
+		c.add(path, EnumChanged, false, "enum was removed")
+
+		return
+	}
+
+	var removed, added []string
+	for _, v := range oldValues {
+		if !slices.Contains(newValues, v) {
+			removed = append(removed, v)
+		}
+	}
+
+	for _, v := range newValues {
+		if !slices.Contains(oldValues, v) {
+			added = append(added, v)
+		}
+	}
+
+	if len(removed) > 0 {
+		c.add(path, EnumChanged, true, fmt.Sprintf("enum values %v were removed", removed))
+	}
+
+	if len(added) > 0 {
+		c.add(path, EnumChanged, false, fmt.Sprintf("enum values %v were added", added))
+	}
+}
+
+func (c *comparer) comparePattern(path string, oldSchema, newSchema *v1beta1.JSONSchemaProps) {
+	switch {
+	case oldSchema.Pattern == newSchema.Pattern:
+		return
+	case newSchema.Pattern == "":
+		c.add(path, PatternChanged, false, fmt.Sprintf("pattern %q was removed", oldSchema.Pattern))
+	case oldSchema.Pattern == "":
+		c.add(path, PatternChanged, true, fmt.Sprintf("pattern %q was added", newSchema.Pattern))
+	default:
+		// we can't tell if one regex is looser than the other, so any change is considered breaking.
+		c.add(path, PatternChanged, true, fmt.Sprintf("pattern changed from %q to %q", oldSchema.Pattern, newSchema.Pattern))
+	}
+}
+
+func (c *comparer) compareBounds(path string, oldSchema, newSchema *v1beta1.JSONSchemaProps) {
+	c.compareLowerBound(path, "minLength", toFloat(oldSchema.MinLength), toFloat(newSchema.MinLength))
+	c.compareUpperBound(path, "maxLength", toFloat(oldSchema.MaxLength), toFloat(newSchema.MaxLength))
+	c.compareLowerBound(path, "minItems", toFloat(oldSchema.MinItems), toFloat(newSchema.MinItems))
+	c.compareUpperBound(path, "maxItems", toFloat(oldSchema.MaxItems), toFloat(newSchema.MaxItems))
+	c.compareLowerBound(path, "minProperties", toFloat(oldSchema.MinProperties), toFloat(newSchema.MinProperties))
+	c.compareUpperBound(path, "maxProperties", toFloat(oldSchema.MaxProperties), toFloat(newSchema.MaxProperties))
+	c.compareLowerBound(path, "minimum", oldSchema.Minimum, newSchema.Minimum)
+	c.compareUpperBound(path, "maximum", oldSchema.Maximum, newSchema.Maximum)
+
+	if !oldSchema.ExclusiveMinimum && newSchema.ExclusiveMinimum {
+		c.add(path, BoundsChanged, true, "minimum became exclusive")
+	}
+
+	if !oldSchema.ExclusiveMaximum && newSchema.ExclusiveMaximum {
+		c.add(path, BoundsChanged, true, "maximum became exclusive")
+	}
+}
+
+// compareLowerBound reports a raised or added lower bound as breaking.
+func (c *comparer) compareLowerBound(path, name string, oldValue, newValue *float64) {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return
+	case oldValue == nil:
+		c.add(path, BoundsChanged, true, fmt.Sprintf("%s %s was added", name, format(*newValue)))
+	case newValue == nil:
+		c.add(path, BoundsChanged, false, fmt.Sprintf("%s %s was removed", name, format(*oldValue)))
+	case *newValue > *oldValue:
+		c.add(path, BoundsChanged, true, fmt.Sprintf("%s raised from %s to %s", name, format(*oldValue), format(*newValue)))
+	case *newValue < *oldValue:
+		c.add(path, BoundsChanged, false, fmt.Sprintf("%s lowered from %s to %s", name, format(*oldValue), format(*newValue)))
+	}
+}
+
+// compareUpperBound reports a lowered or added upper bound as breaking.
+func (c *comparer) compareUpperBound(path, name string, oldValue, newValue *float64) {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return
+	case oldValue == nil:
+		c.add(path, BoundsChanged, true, fmt.Sprintf("%s %s was added", name, format(*newValue)))
+	case newValue == nil:
+		c.add(path, BoundsChanged, false, fmt.Sprintf("%s %s was removed", name, format(*oldValue)))
+	case *newValue < *oldValue:
+		c.add(path, BoundsChanged, true, fmt.Sprintf("%s lowered from %s to %s", name, format(*oldValue), format(*newValue)))
+	case *newValue > *oldValue:
+		c.add(path, BoundsChanged, false, fmt.Sprintf("%s raised from %s to %s", name, format(*oldValue), format(*newValue)))
+	}
+}
+
+// versions returns the schema of each version.
+func versions(crd *pkg.SchemaType) map[string]*v1beta1.JSONSchemaProps {
+	result := map[string]*v1beta1.JSONSchemaProps{}
+	for _, v := range crd.VersionsOrValidation() {
+		result[v.Name] = v.Schema
+	}
+
+	return result
+}
+
+func enumValues(enum []v1beta1.JSON) []string {
+	result := make([]string, 0, len(enum))
+	for _, e := range enum {
+		result = append(result, string(e.Raw))
+	}
+
+	return result
+}
+
+func key(crd *pkg.SchemaType) string {
+	return crd.Group + "/" + crd.Kind
+}
+
+func toFloat(v *int64) *float64 {
+	if v == nil {
+		return nil
+	}
+
+	f := float64(*v)
+
+	return &f
+}
+
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestCompare(t *testing.T) {
+	oldContent, err := os.ReadFile(filepath.Join("testdata", "old.yaml"))
+	require.NoError(t, err)
+
+	oldCRDs, err := pkg.ExtractSchemaTypes(oldContent)
+	require.NoError(t, err)
+
+	newContent, err := os.ReadFile(filepath.Join("testdata", "new.yaml"))
+	require.NoError(t, err)
+
+	newCRDs, err := pkg.ExtractSchemaTypes(newContent)
+	require.NoError(t, err)
+
+	report := Compare(oldCRDs, newCRDs)
+
+	type result struct {
+		Version  string
+		Path     string
+		Type     ChangeType
+		Breaking bool
+	}
+
+	got := make([]result, 0, len(report.Changes))
+	for _, c := range report.Changes {
+		got = append(got, result{Version: c.Version, Path: c.Path, Type: c.Type, Breaking: c.Breaking})
+	}
+
+	assert.Equal(t, []result{
+		{Version: "v1", Path: ".spec.added", Type: RequiredAdded, Breaking: true},
+		{Version: "v1", Path: ".spec.items[*]", Type: TypeChanged, Breaking: true},
+		{Version: "v1", Path: ".spec.mode", Type: EnumChanged, Breaking: true},
+		{Version: "v1", Path: ".spec.mode", Type: EnumChanged, Breaking: false},
+		{Version: "v1", Path: ".spec.name", Type: PatternChanged, Breaking: true},
+		{Version: "v1", Path: ".spec.name", Type: BoundsChanged, Breaking: true},
+		{Version: "v1", Path: ".spec.removed", Type: FieldRemoved, Breaking: true},
+		{Version: "v1", Path: ".spec.replicas", Type: TypeChanged, Breaking: true},
+		{Version: "v1", Path: ".spec.added", Type: FieldAdded, Breaking: false},
+		{Version: "v1alpha1", Type: VersionRemoved, Breaking: true},
+		{Version: "v2", Type: VersionAdded, Breaking: false},
+	}, got)
+	assert.Equal(t, 8, report.Breaking())
+}
+
+func TestCompareLoosenedConstraints(t *testing.T) {
+	oldContent, err := os.ReadFile(filepath.Join("testdata", "loosened_old.yaml"))
+	require.NoError(t, err)
+
+	oldCRDs, err := pkg.ExtractSchemaTypes(oldContent)
+	require.NoError(t, err)
+
+	newContent, err := os.ReadFile(filepath.Join("testdata", "loosened_new.yaml"))
+	require.NoError(t, err)
+
+	newCRDs, err := pkg.ExtractSchemaTypes(newContent)
+	require.NoError(t, err)
+
+	report := Compare(oldCRDs, newCRDs)
+
+	assert.Len(t, report.Changes, 3)
+	assert.Equal(t, 0, report.Breaking())
+}
+
+func TestCompareRemovedCRD(t *testing.T) {
+	oldContent, err := os.ReadFile(filepath.Join("testdata", "removed_old.yaml"))
+	require.NoError(t, err)
+
+	oldCRDs, err := pkg.ExtractSchemaTypes(oldContent)
+	require.NoError(t, err)
+
+	newContent, err := os.ReadFile(filepath.Join("testdata", "removed_new.yaml"))
+	require.NoError(t, err)
+
+	newCRDs, err := pkg.ExtractSchemaTypes(newContent)
+	require.NoError(t, err)
+
+	report := Compare(oldCRDs, newCRDs)
+
+	assert.Equal(t, []Change{
+		{Group: "group", Kind: "Kind", Type: CRDRemoved, Breaking: true, Message: "CRD was removed"},
+		{Group: "group", Kind: "Other", Type: CRDAdded, Message: "CRD was added"},
+	}, report.Changes)
+}
@@ -1,15 +1,85 @@
 package pkg
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
 )
 
+// DecoderBufferSize is the buffer size of YAML or JSON decoders. It's only used to detect whether the
+// content is JSON or YAML, documents can be of any size.
+const DecoderBufferSize = 4096
+
+// definitionKinds are the kinds of the objects schemas are extracted from. The CompositeResourceDefinitions
+// of Crossplane define their schemas the same way CRDs do.
+var definitionKinds = []string{"CustomResourceDefinition", "CompositeResourceDefinition"}
+
+// ContainsCRD returns true if content might contain a CRD. Every CRD defines an openAPIV3Schema, regardless of
+// whether it's written as YAML or JSON, so files can be recognised without looking at their extension.
+func ContainsCRD(content []byte) bool {
+	return bytes.Contains(content, []byte("openAPIV3Schema"))
+}
+
+// ExtractSchemaTypes extracts the schema types of every CRD in content. Content can contain multiple
+// YAML or JSON documents and lists of CRDs. Objects that aren't CRDs or CompositeResourceDefinitions are skipped.
+func ExtractSchemaTypes(content []byte) ([]*SchemaType, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), DecoderBufferSize)
+
+	var result []*SchemaType
+	for {
+		document := map[string]any{}
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+
+			return nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+
+		if len(document) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: document}
+		objects := []*unstructured.Unstructured{obj}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read list: %w", err)
+			}
+
+			objects = objects[:0]
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		}
+
+		for _, o := range objects {
+			// other objects, like namespaces and deployments, are often part of CRD bundles.
+			if !slices.Contains(definitionKinds, o.GetKind()) {
+				continue
+			}
+
+			schemaType, err := ExtractSchemaType(o)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract schema type of %s: %w", o.GetName(), err)
+			}
+
+			if schemaType != nil {
+				result = append(result, schemaType)
+			}
+		}
+	}
+}
+
 // ExtractSchemaType makes sure the following required fields are
 // present in the unstructured data and creates are own internal representation:
 // - spec
@@ -54,6 +124,19 @@ func ExtractSchemaType(obj *unstructured.Unstructured) (*SchemaType, error) {
 		Group: group,
 		Kind:  kind,
 	}
+	extractMetadata(spec, schemaTypes)
+
+	// v1beta1 CRDs define these for all versions at the spec level.
+	topLevelColumns, err := extractPrinterColumns(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	topLevelSubresources, err := extractSubresources(spec)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, v := range versionsList {
 		vMap, ok := v.(map[string]interface{})
 		if !ok {
@@ -83,11 +166,34 @@ func ExtractSchemaType(obj *unstructured.Unstructured) (*SchemaType, error) {
 			return nil, err
 		}
 
-		ensureKindAndAPIVersionIsSet(schemaValue.Properties)
+		ensureKindAndAPIVersionIsSet(schemaValue)
+
+		columns, err := extractPrinterColumns(vMap)
+		if err != nil {
+			return nil, err
+		}
+		if columns == nil {
+			columns = topLevelColumns
+		}
+
+		subresources, err := extractSubresources(vMap)
+		if err != nil {
+			return nil, err
+		}
+		if subresources == nil {
+			subresources = topLevelSubresources
+		}
+
+		deprecated, _ := vMap["deprecated"].(bool)
+		deprecationWarning, _ := vMap["deprecationWarning"].(string)
 
 		version := &CRDVersion{
-			Name:   name,
-			Schema: schemaValue,
+			Name:               name,
+			Schema:             schemaValue,
+			PrinterColumns:     columns,
+			Subresources:       subresources,
+			Deprecated:         deprecated,
+			DeprecationWarning: deprecationWarning,
 		}
 
 		schemaTypes.Versions = append(schemaTypes.Versions, version)
@@ -116,25 +222,138 @@ func extractValidation(obj *unstructured.Unstructured, specMap map[string]any) (
 		return nil, err
 	}
 
-	ensureKindAndAPIVersionIsSet(props.Properties)
+	ensureKindAndAPIVersionIsSet(props)
 
 	kindValue, groupValue, err := extractGroupKind(specMap)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SchemaType{
+	columns, err := extractPrinterColumns(specMap)
+	if err != nil {
+		return nil, err
+	}
+
+	subresources, err := extractSubresources(specMap)
+	if err != nil {
+		return nil, err
+	}
+
+	// the version is deprecated in favour of versions, so it's optional.
+	version, _ := extractValue[string](specMap, "version")
+
+	schemaType := &SchemaType{
 		Schema: nil,
 		Validation: &Validation{
-			Schema: props,
-			Name:   obj.GetName(),
+			Schema:         props,
+			Name:           obj.GetName(),
+			Version:        version,
+			PrinterColumns: columns,
+			Subresources:   subresources,
 		},
 		Group: groupValue,
 		Kind:  kindValue,
-	}, nil
+	}
+	extractMetadata(specMap, schemaType)
+
+	return schemaType, nil
+}
+
+// extractMetadata sets the optional scope, names and conversion information of the CRD.
+// None of these are required, so missing values are simply left empty.
+func extractMetadata(specMap map[string]any, schemaType *SchemaType) {
+	schemaType.Scope, _ = extractValue[string](specMap, "scope")
+
+	if names, ok := specMap["names"]; ok {
+		schemaType.Plural, _ = extractValue[string](names, "plural")
+		schemaType.ShortNames = extractStringList(names, "shortNames")
+		schemaType.Categories = extractStringList(names, "categories")
+	}
+
+	if conversion, ok := specMap["conversion"]; ok {
+		schemaType.Conversion, _ = extractValue[string](conversion, "strategy")
+	}
+}
+
+// extractPrinterColumns reads additionalPrinterColumns. v1beta1 CRDs use `JSONPath` as the key
+// for the path whereas v1 CRDs use `jsonPath`, so both are accepted.
+func extractPrinterColumns(m map[string]any) ([]PrinterColumn, error) {
+	columns, err := extractValue[[]any](m, "additionalPrinterColumns")
+	if err != nil {
+		// no columns defined
+		return nil, nil //nolint:nilerr // optional field
+	}
+
+	result := make([]PrinterColumn, 0, len(columns))
+	for _, c := range columns {
+		content, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+
+		column := PrinterColumn{}
+		if err := json.Unmarshal(content, &column); err != nil {
+			return nil, fmt.Errorf("invalid additional printer column: %w", err)
+		}
+
+		if column.JSONPath == "" {
+			column.JSONPath, _ = extractValue[string](c, "JSONPath")
+		}
+
+		result = append(result, column)
+	}
+
+	return result, nil
 }
 
-func ensureKindAndAPIVersionIsSet(properties map[string]v1beta1.JSONSchemaProps) {
+func extractSubresources(m map[string]any) (*Subresources, error) {
+	subresources, err := extractValue[map[string]any](m, "subresources")
+	if err != nil {
+		return nil, nil //nolint:nilerr // optional field
+	}
+
+	result := &Subresources{}
+	if _, ok := subresources["status"]; ok {
+		result.Status = true
+	}
+
+	if scale, ok := subresources["scale"]; ok {
+		content, err := json.Marshal(scale)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Scale = &ScaleSubresource{}
+		if err := json.Unmarshal(content, result.Scale); err != nil {
+			return nil, fmt.Errorf("invalid scale subresource: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func extractStringList(m any, k string) []string {
+	list, err := extractValue[[]any](m, k)
+	if err != nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(list))
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+func ensureKindAndAPIVersionIsSet(schema *v1beta1.JSONSchemaProps) {
+	if schema.Properties == nil {
+		schema.Properties = map[string]v1beta1.JSONSchemaProps{}
+	}
+
+	properties := schema.Properties
 	if _, ok := properties["kind"]; !ok {
 		properties["kind"] = v1beta1.JSONSchemaProps{}
 	}
@@ -81,3 +81,224 @@ func TestExtractSchemaTypeForValidation(t *testing.T) {
 	assert.Equal(t, "id", schemaType.Validation.Schema.ID)
 	assert.Equal(t, "title", schemaType.Validation.Schema.Title)
 }
+
+func TestExtractSchemaTypeMetadata(t *testing.T) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "this-is-my-name",
+			},
+			"spec": map[string]interface{}{
+				"group": "group",
+				"scope": "Cluster",
+				"names": map[string]interface{}{
+					"kind":       "kind",
+					"plural":     "kinds",
+					"shortNames": []any{"k", "kd"},
+					"categories": []any{"all"},
+				},
+				"conversion": map[string]interface{}{
+					"strategy": "Webhook",
+				},
+				"versions": []any{
+					map[string]interface{}{
+						"name":               "v1",
+						"deprecated":         true,
+						"deprecationWarning": "use v2",
+						"schema": map[string]interface{}{
+							"openAPIV3Schema": map[string]interface{}{
+								"type":       "object",
+								"properties": map[string]interface{}{},
+							},
+						},
+						"additionalPrinterColumns": []any{
+							map[string]interface{}{
+								"name":     "Ready",
+								"type":     "string",
+								"jsonPath": ".status.ready",
+							},
+						},
+						"subresources": map[string]interface{}{
+							"status": map[string]interface{}{},
+							"scale": map[string]interface{}{
+								"specReplicasPath":   ".spec.replicas",
+								"statusReplicasPath": ".status.replicas",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	schemaType, err := ExtractSchemaType(obj)
+	require.NoError(t, err)
+	assert.Equal(t, "Cluster", schemaType.Scope)
+	assert.Equal(t, "kinds", schemaType.Plural)
+	assert.Equal(t, []string{"k", "kd"}, schemaType.ShortNames)
+	assert.Equal(t, []string{"all"}, schemaType.Categories)
+	assert.Equal(t, "Webhook", schemaType.Conversion)
+	assert.Equal(t, []PrinterColumn{{Name: "Ready", Type: "string", JSONPath: ".status.ready"}}, schemaType.Versions[0].PrinterColumns)
+	require.NotNil(t, schemaType.Versions[0].Subresources)
+	assert.True(t, schemaType.Versions[0].Subresources.Status)
+	assert.Equal(t, ".spec.replicas", schemaType.Versions[0].Subresources.Scale.SpecReplicasPath)
+	assert.True(t, schemaType.Versions[0].Deprecated)
+	assert.Equal(t, "use v2", schemaType.Versions[0].DeprecationWarning)
+}
+
+func TestRenderingOptions(t *testing.T) {
+	opts := RenderOpts{Comments: true, Minimal: false, Random: true}
+	assert.Equal(t, opts, Rendering{}.Options(opts))
+
+	minimal, comments := true, false
+	assert.Equal(t, RenderOpts{Comments: false, Minimal: true, Random: true}, Rendering{Minimal: &minimal, Comments: &comments}.Options(opts))
+}
+
+func TestExtractSchemaTypeMetadataForValidation(t *testing.T) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1beta1",
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "this-is-my-name",
+			},
+			"spec": map[string]interface{}{
+				"group": "group",
+				"scope": "Namespaced",
+				"names": map[string]interface{}{
+					"kind": "kind",
+				},
+				"additionalPrinterColumns": []any{
+					map[string]interface{}{
+						"name":     "Age",
+						"type":     "date",
+						"JSONPath": ".metadata.creationTimestamp",
+					},
+				},
+				"subresources": map[string]interface{}{
+					"status": map[string]interface{}{},
+				},
+				"validation": map[string]interface{}{
+					"openAPIV3Schema": map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{},
+					},
+				},
+			},
+		},
+	}
+
+	schemaType, err := ExtractSchemaType(obj)
+	require.NoError(t, err)
+	assert.Equal(t, "Namespaced", schemaType.Scope)
+	assert.Equal(t, []PrinterColumn{{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"}}, schemaType.Validation.PrinterColumns)
+	assert.True(t, schemaType.Validation.Subresources.Status)
+	assert.Nil(t, schemaType.Validation.Subresources.Scale)
+}
+
+func TestExtractSchemaTypes(t *testing.T) {
+	content := []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: system
+---
+apiVersion: example.com/v1
+kind: Release
+metadata:
+  name: not-a-crd
+spec:
+  validation: enabled
+  versions: latest
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: firsts.group
+spec:
+  group: group
+  names:
+    kind: First
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apiextensions.k8s.io/v1
+  kind: CustomResourceDefinition
+  metadata:
+    name: seconds.group
+  spec:
+    group: group
+    names:
+      kind: Second
+    versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+- apiVersion: apiextensions.k8s.io/v1
+  kind: CustomResourceDefinition
+  metadata:
+    name: thirds.group
+  spec:
+    group: group
+    names:
+      kind: Third
+    versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xfourths.group
+spec:
+  group: group
+  names:
+    kind: XFourth
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+`)
+
+	schemaTypes, err := ExtractSchemaTypes(content)
+	require.NoError(t, err)
+
+	kinds := make([]string, 0, len(schemaTypes))
+	for _, schemaType := range schemaTypes {
+		kinds = append(kinds, schemaType.Kind)
+	}
+
+	assert.Equal(t, []string{"First", "Second", "Third", "XFourth"}, kinds)
+}
+
+func TestExtractSchemaTypesJSON(t *testing.T) {
+	content := []byte(`{
+  "apiVersion": "apiextensions.k8s.io/v1",
+  "kind": "CustomResourceDefinition",
+  "metadata": {"name": "firsts.group"},
+  "spec": {
+    "group": "group",
+    "names": {"kind": "First"},
+    "versions": [{"name": "v1", "schema": {"openAPIV3Schema": {"type": "object"}}}]
+  }
+}`)
+
+	assert.True(t, ContainsCRD(content))
+	assert.False(t, ContainsCRD([]byte("apiVersion: v1\nkind: Namespace\n")))
+
+	schemaTypes, err := ExtractSchemaTypes(content)
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 1)
+	assert.Equal(t, "First", schemaTypes[0].Kind)
+}
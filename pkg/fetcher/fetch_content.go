@@ -5,14 +5,34 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+// maxRetryAfter is the longest wait a server can ask for with a Retry-After header.
+var maxRetryAfter = time.Minute
+
+// Cache stores fetched content so it can be revalidated with the server instead of being
+// downloaded again.
+type Cache interface {
+	// Get returns the cached content of a url and the ETag and Last-Modified headers it was served with.
+	Get(url string) (content []byte, etag, lastModified string, ok bool)
+	// Put stores the content of a url and the ETag and Last-Modified headers it was served with.
+	Put(url string, content []byte, etag, lastModified string) error
+	// Offline returns true if content must only be read from the cache.
+	Offline() bool
+}
+
 // Fetcher wraps an HTTP client.
 type Fetcher struct {
 	client   *http.Client
 	username string
 	password string
 	token    string
+	cache    Cache
+	headers  map[string]string
+	retries  int
+	backoff  time.Duration
 }
 
 // NewFetcher constructs a new client wrapper with a given client.
@@ -25,24 +45,51 @@ func NewFetcher(client *http.Client, username, password, token string) *Fetcher
 	}
 }
 
+// WithCache makes the fetcher store content in cache and revalidate it on the next fetch.
+func (f *Fetcher) WithCache(cache Cache) *Fetcher {
+	f.cache = cache
+
+	return f
+}
+
+// WithHeaders adds headers to every request of the fetcher.
+func (f *Fetcher) WithHeaders(headers map[string]string) *Fetcher {
+	f.headers = headers
+
+	return f
+}
+
+// WithRetries makes the fetcher retry requests that fail with a 5xx or 429 status code. The first retry
+// waits for backoff, which doubles with every retry, unless the server asks for a different wait with
+// a Retry-After header. The wait asked for by the server is capped at a minute.
+func (f *Fetcher) WithRetries(retries int, backoff time.Duration) *Fetcher {
+	f.retries = retries
+	f.backoff = backoff
+
+	return f
+}
+
 // Fetch constructs a request and does a client.Do with it.
 func (f *Fetcher) Fetch(url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate request for url '%s': %w", url, err)
-	}
-
-	if f.username != "" && f.password != "" {
-		req.SetBasicAuth(f.username, f.password)
-	}
+	var (
+		cached             []byte
+		etag, lastModified string
+		ok                 bool
+	)
+	if f.cache != nil {
+		cached, etag, lastModified, ok = f.cache.Get(url)
+		if f.cache.Offline() {
+			if !ok {
+				return nil, fmt.Errorf("url '%s' is not cached and fetching is disabled in offline mode", url)
+			}
 
-	if f.token != "" {
-		req.Header.Add("Authorization", "Bearer "+f.token)
+			return cached, nil
+		}
 	}
 
-	resp, err := f.client.Do(req)
+	resp, err := f.do(url, etag, lastModified)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch data: %w", err)
+		return nil, err
 	}
 
 	defer func() {
@@ -51,6 +98,10 @@ func (f *Fetcher) Fetch(url string) ([]byte, error) {
 		}
 	}()
 
+	if ok && resp.StatusCode == http.StatusNotModified {
+		return cached, nil
+	}
+
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		return nil, fmt.Errorf("failed to fetch url content with status code %d", resp.StatusCode)
 	}
@@ -60,5 +111,70 @@ func (f *Fetcher) Fetch(url string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to read body: %w", err)
 	}
 
+	if f.cache != nil {
+		if err := f.cache.Put(url, content, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")); err != nil {
+			return nil, fmt.Errorf("failed to cache content of url '%s': %w", url, err)
+		}
+	}
+
 	return content, nil
 }
+
+// do sends the request for url and retries it while the server responds with a retryable status code.
+func (f *Fetcher) do(url, etag, lastModified string) (*http.Response, error) {
+	backoff := f.backoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate request for url '%s': %w", url, err)
+		}
+
+		for k, v := range f.headers {
+			req.Header.Set(k, v)
+		}
+
+		if f.username != "" && f.password != "" {
+			req.SetBasicAuth(f.username, f.password)
+		}
+
+		if f.token != "" {
+			req.Header.Set("Authorization", "Bearer "+f.token)
+		}
+
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+
+		resp, err := f.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch data: %w", err)
+		}
+
+		if attempt >= f.retries || !retryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		wait := backoff
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait = min(time.Duration(seconds)*time.Second, maxRetryAfter)
+		}
+
+		// the body is drained, so the connection can be reused by the next attempt.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if err := resp.Body.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close body: %w", err)
+		}
+
+		time.Sleep(wait)
+		backoff *= 2
+	}
+}
+
+// retryable returns true if a request failing with status code might succeed when it's sent again.
+func retryable(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
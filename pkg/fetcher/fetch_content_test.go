@@ -0,0 +1,119 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/cache"
+)
+
+func TestFetchWithCache(t *testing.T) {
+	content := "version: 1"
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"1"` && content == "version: 1" {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"1"`)
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	f := NewFetcher(server.Client(), "", "", "").WithCache(cache.New(dir, false))
+
+	got, err := f.Fetch(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1", string(got))
+
+	got, err = f.Fetch(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1", string(got))
+	assert.Equal(t, 1, notModified)
+
+	content = "version: 2"
+	got, err = f.Fetch(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 2", string(got))
+
+	offline := NewFetcher(server.Client(), "", "", "").WithCache(cache.New(dir, true))
+	got, err = offline.Fetch(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 2", string(got))
+	assert.Equal(t, 3, requests)
+
+	_, err = offline.Fetch(server.URL + "/missing")
+	require.Error(t, err)
+}
+
+func TestFetchWithRetriesAndHeaders(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte("version: 1"))
+		}
+	}))
+	defer server.Close()
+
+	f := NewFetcher(server.Client(), "", "", "").
+		WithHeaders(map[string]string{"X-Api-Key": "secret"}).
+		WithRetries(2, time.Millisecond)
+
+	got, err := f.Fetch(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1", string(got))
+	assert.Equal(t, 3, requests)
+
+	requests = 0
+	f.WithRetries(1, time.Millisecond)
+	_, err = f.Fetch(server.URL)
+	require.ErrorContains(t, err, "status code 429")
+	assert.Equal(t, 2, requests)
+}
+
+func TestFetchCapsRetryAfter(t *testing.T) {
+	defer func(wait time.Duration) { maxRetryAfter = wait }(maxRetryAfter)
+	maxRetryAfter = 10 * time.Millisecond
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("version: 1"))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(server.Client(), "", "", "").WithRetries(1, time.Millisecond)
+
+	start := time.Now()
+	got, err := f.Fetch(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1", string(got))
+	assert.Equal(t, 2, requests)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -27,6 +27,10 @@ func Generate(crd *SchemaType, w io.WriteCloser, enableComments, minimal, skipRa
 		}
 	}()
 
+	if err := crd.ResolveRefs(); err != nil {
+		return fmt.Errorf("failed to resolve references: %w", err)
+	}
+
 	parser := NewParser(crd.Group, crd.Kind, enableComments, minimal, skipRandom)
 	for i, version := range crd.Versions {
 		if err := parser.ParseProperties(version.Name, w, version.Schema.Properties, RootRequiredFields); err != nil {
@@ -0,0 +1,87 @@
+package glob
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// doubleStar is a segment of a pattern that matches any number of directories.
+const doubleStar = "**"
+
+// Pattern is a compiled glob pattern that matches slash separated paths.
+type Pattern struct {
+	segments []string
+}
+
+// Compile parses a glob pattern. The segments of the pattern between slashes are matched against
+// the segments of a path with path.Match, and a ** segment matches any number of segments. A pattern
+// without a / matches the file name in any directory.
+func Compile(pattern string) (Pattern, error) {
+	if !strings.Contains(pattern, "/") {
+		pattern = doubleStar + "/" + pattern
+	}
+
+	segments := strings.Split(pattern, "/")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return Pattern{}, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+	}
+
+	return Pattern{segments: segments}, nil
+}
+
+// CompileAll compiles every pattern and returns the first error.
+func CompileAll(patterns []string) ([]Pattern, error) {
+	result := make([]Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, err := Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+// Match returns true if the slash separated name matches the pattern.
+func (p Pattern) Match(name string) bool {
+	return match(p.segments, strings.Split(name, "/"))
+}
+
+// MatchAny returns true if name matches any of the patterns.
+func MatchAny(patterns []Pattern, name string) bool {
+	return slices.ContainsFunc(patterns, func(p Pattern) bool { return p.Match(name) })
+}
+
+func match(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == doubleStar {
+			// try to match the rest of the pattern after skipping any number of segments.
+			for i := range len(name) + 1 {
+				if match(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		// the pattern is validated by Compile.
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
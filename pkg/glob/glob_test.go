@@ -0,0 +1,60 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "crd.yaml", name: "crd.yaml", want: true},
+		{pattern: "crd.yaml", name: "config/crd/crd.yaml", want: true},
+		{pattern: "*.yaml", name: "config/crd/bases/bars.yaml", want: true},
+		{pattern: "*.yaml", name: "config/crd/bases/bars.yml"},
+		{pattern: "**/bases/*.yaml", name: "bases/bars.yaml", want: true},
+		{pattern: "**/bases/*.yaml", name: "config/crd/bases/bars.yaml", want: true},
+		{pattern: "**/bases/*.yaml", name: "config/crd/bases/nested/bars.yaml"},
+		{pattern: "config/crd/**", name: "config/crd/bars.yaml", want: true},
+		{pattern: "config/crd/**", name: "config/crd/bases/bars.yaml", want: true},
+		{pattern: "config/crd/**", name: "config/crds/bars.yaml"},
+		{pattern: "config/**/bars.yaml", name: "config/bars.yaml", want: true},
+		{pattern: "config/*/bars.yaml", name: "config/crd/bases/bars.yaml"},
+		{pattern: "config/crd/*.yaml", name: "other/config/crd/bars.yaml"},
+		{pattern: "v?/crd.yaml", name: "v1/crd.yaml", want: true},
+		{pattern: "v?/crd.yaml", name: "v10/crd.yaml"},
+		{pattern: "v?/crd.yaml", name: "v/crd.yaml"},
+		{pattern: "crds/(a|b)+.yaml", name: "crds/(a|b)+.yaml", want: true},
+		{pattern: "crds/a.yaml", name: "crds/aXyaml"},
+		{pattern: "crds/$bar^.yaml", name: "crds/$bar^.yaml", want: true},
+		{pattern: "crds/[ab].yaml", name: "crds/b.yaml", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			p, err := Compile(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p.Match(tt.name))
+		})
+	}
+}
+
+func TestCompileAllWithInvalidPattern(t *testing.T) {
+	_, err := CompileAll([]string{"*.yaml", "crds/[a-.yaml"})
+	assert.EqualError(t, err, "invalid pattern crds/[a-.yaml: syntax error in pattern")
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns, err := CompileAll([]string{"*.yml", "config/**"})
+	require.NoError(t, err)
+
+	assert.True(t, MatchAny(patterns, "crds/bars.yml"))
+	assert.True(t, MatchAny(patterns, "config/crd/bars.yaml"))
+	assert.False(t, MatchAny(patterns, "crds/bars.yaml"))
+	assert.False(t, MatchAny(nil, "crds/bars.yaml"))
+}
@@ -0,0 +1,91 @@
+package helm
+
+import (
+	"fmt"
+	"slices"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+const (
+	releaseName      = "cty"
+	releaseNamespace = "default"
+)
+
+// Manifest is a file that contains CRDs.
+type Manifest struct {
+	// Name is the path of the file. For charts, it's prefixed with the names of the chart and its parent charts.
+	Name    string
+	Content []byte
+}
+
+// RenderError is returned together with the files in the crds directories of a chart whose templates
+// can't be rendered. CRDs defined in the templates are missing from the manifests.
+type RenderError struct {
+	Chart string
+	Err   error
+}
+
+func (e *RenderError) Error() string {
+	return fmt.Sprintf("failed to render templates of chart %s: %s", e.Chart, e.Err)
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// Manifests returns the files of the chart and its enabled sub-charts that contain CRDs. These are
+// the files in the crds directories and the templates rendered with the default values of the chart.
+// Templates are rendered the way helm lint does, so required values the defaults don't set and calls
+// to fail don't stop the rendering. If the templates still can't be rendered, the files in the crds
+// directories are returned on their own together with a *RenderError, and only if there are none the
+// error is returned alone.
+func Manifests(ch *chart.Chart) ([]Manifest, error) {
+	// removes sub-charts that are disabled by a condition or tag and imports values of sub-charts.
+	if err := chartutil.ProcessDependenciesWithMerge(ch, ch.Values); err != nil {
+		return nil, fmt.Errorf("failed to process dependencies of chart %s: %w", ch.Name(), err)
+	}
+
+	var manifests []Manifest
+	for _, crd := range ch.CRDObjects() {
+		manifests = append(manifests, Manifest{Name: crd.Filename, Content: crd.File.Data})
+	}
+
+	values, err := chartutil.ToRenderValues(ch, nil, chartutil.ReleaseOptions{
+		Name:      releaseName,
+		Namespace: releaseNamespace,
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute values of chart %s: %w", ch.Name(), err)
+	}
+
+	rendered, err := engine.Engine{LintMode: true}.Render(ch, values)
+	if err != nil {
+		if len(manifests) > 0 {
+			return manifests, &RenderError{Chart: ch.Name(), Err: err}
+		}
+
+		return nil, fmt.Errorf("failed to render chart %s: %w", ch.Name(), err)
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name, content := range rendered {
+		if pkg.ContainsCRD([]byte(content)) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		manifests = append(manifests, Manifest{Name: name, Content: []byte(rendered[name])})
+	}
+
+	return manifests, nil
+}
@@ -0,0 +1,97 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestManifests(t *testing.T) {
+	ch, err := loader.Load("testdata/chart")
+	require.NoError(t, err)
+
+	manifests, err := Manifests(ch)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(manifests))
+	kinds := make([]string, 0, len(manifests))
+	for _, m := range manifests {
+		names = append(names, m.Name)
+
+		schemaTypes, err := pkg.ExtractSchemaTypes(m.Content)
+		require.NoError(t, err)
+
+		for _, s := range schemaTypes {
+			kinds = append(kinds, s.Group+"/"+s.Kind)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"chart/crds/bars.yaml",
+		"chart/charts/sub/crds/quxes.yaml",
+		"chart/charts/sub/templates/crd.yaml",
+		"chart/templates/crds.yaml",
+	}, names)
+	assert.Equal(t, []string{
+		"example.com/Bar",
+		"sub.example.com/Qux",
+		"sub.example.com/Quxx",
+		"example.com/Baz",
+	}, kinds)
+}
+
+func TestManifestsArchive(t *testing.T) {
+	ch, err := loader.Load("testdata/chart")
+	require.NoError(t, err)
+
+	archive, err := chartutil.Save(ch, t.TempDir())
+	require.NoError(t, err)
+
+	ch, err = loader.Load(archive)
+	require.NoError(t, err)
+
+	manifests, err := Manifests(ch)
+	require.NoError(t, err)
+	assert.Len(t, manifests, 4)
+}
+
+func TestManifestsWithMissingRequiredValues(t *testing.T) {
+	ch, err := loader.Load("testdata/required-chart")
+	require.NoError(t, err)
+
+	manifests, err := Manifests(ch)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(manifests))
+	for _, m := range manifests {
+		names = append(names, m.Name)
+	}
+
+	assert.Equal(t, []string{
+		"required-chart/crds/widgets.yaml",
+		"required-chart/templates/crds.yaml",
+	}, names)
+}
+
+func TestManifestsWithTemplatesThatFailToRender(t *testing.T) {
+	ch, err := loader.Load("testdata/broken-chart")
+	require.NoError(t, err)
+
+	manifests, err := Manifests(ch)
+	var renderErr *RenderError
+	require.ErrorAs(t, err, &renderErr)
+	assert.Equal(t, "broken-chart", renderErr.Chart)
+	require.Len(t, manifests, 1)
+	assert.Equal(t, "broken-chart/crds/widgets.yaml", manifests[0].Name)
+
+	// without CRDs in the crds directory, there is nothing to fall back to.
+	ch.Files = nil
+
+	_, err = Manifests(ch)
+	assert.ErrorContains(t, err, "failed to render chart broken-chart")
+}
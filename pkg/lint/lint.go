@@ -0,0 +1,219 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// Severity of a finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// rank orders severities so a threshold can be applied.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3 //nolint:mnd // highest
+	case SeverityWarning:
+		return 2 //nolint:mnd // middle
+	case SeverityInfo:
+		return 1
+	}
+
+	return 0
+}
+
+// AtLeast returns true if the severity is the same or higher than the given severity.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() >= other.rank()
+}
+
+// ParseSeverity validates a severity given by the user.
+func ParseSeverity(s string) (Severity, error) {
+	severity := Severity(s)
+	if severity.rank() == 0 {
+		return "", fmt.Errorf("unknown severity %q, must be one of error, warning, info", s)
+	}
+
+	return severity, nil
+}
+
+// Rule is a single lint check. A rule either checks every field or every version of a CRD.
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity
+	// Field is called for every field in every version with the path of the field.
+	Field func(path string, schema *v1beta1.JSONSchemaProps) []string
+	// Version is called once for every version of a CRD.
+	Version func(crd *pkg.SchemaType, version *pkg.CRDVersion) []string
+}
+
+// Finding is a single problem found by a rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Group    string   `json:"group"`
+	Kind     string   `json:"kind"`
+	Version  string   `json:"version"`
+	Path     string   `json:"path,omitempty"`
+	Message  string   `json:"message"`
+	Location string   `json:"location,omitempty"`
+}
+
+// RuleConfig configures a single rule.
+type RuleConfig struct {
+	Disabled bool     `json:"disabled,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// Config configures the linter. Rules that are not mentioned use their defaults.
+type Config struct {
+	// Enable is a list of rules to run. If empty, all rules are run.
+	Enable []string `json:"enable,omitempty"`
+	// Disable is a list of rules to skip.
+	Disable []string              `json:"disable,omitempty"`
+	Rules   map[string]RuleConfig `json:"rules,omitempty"`
+}
+
+// Linter runs a set of rules over CRDs.
+type Linter struct {
+	rules []Rule
+}
+
+// NewLinter creates a linter with the default rules configured by the given configuration.
+func NewLinter(config Config) (*Linter, error) {
+	known := make(map[string]struct{}, len(DefaultRules))
+	for _, r := range DefaultRules {
+		known[r.Name] = struct{}{}
+	}
+
+	names := slices.Concat(config.Enable, config.Disable)
+	for name := range config.Rules {
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+
+	var rules []Rule
+	for _, r := range DefaultRules {
+		if len(config.Enable) > 0 && !slices.Contains(config.Enable, r.Name) {
+			continue
+		}
+
+		if slices.Contains(config.Disable, r.Name) {
+			continue
+		}
+
+		if c, ok := config.Rules[r.Name]; ok {
+			if c.Disabled {
+				continue
+			}
+
+			if c.Severity != "" {
+				severity, err := ParseSeverity(string(c.Severity))
+				if err != nil {
+					return nil, fmt.Errorf("invalid configuration for rule %s: %w", r.Name, err)
+				}
+
+				r.Severity = severity
+			}
+		}
+
+		rules = append(rules, r)
+	}
+
+	return &Linter{rules: rules}, nil
+}
+
+// Rules returns the rules the linter runs.
+func (l *Linter) Rules() []Rule {
+	return l.rules
+}
+
+// Lint runs all rules for every version of every CRD.
+func (l *Linter) Lint(crds []*pkg.SchemaType) []Finding {
+	var findings []Finding
+
+	for _, crd := range crds {
+		for _, version := range crd.VersionsOrValidation() {
+			add := func(r Rule, path string, messages []string) {
+				for _, m := range messages {
+					findings = append(findings, Finding{
+						Rule:     r.Name,
+						Severity: r.Severity,
+						Group:    crd.Group,
+						Kind:     crd.Kind,
+						Version:  version.Name,
+						Path:     path,
+						Message:  m,
+						Location: crd.Location,
+					})
+				}
+			}
+
+			for _, r := range l.rules {
+				if r.Version != nil {
+					add(r, "", r.Version(crd, version))
+				}
+			}
+
+			walk(version.Schema, func(path string, schema *v1beta1.JSONSchemaProps) {
+				for _, r := range l.rules {
+					if r.Field != nil {
+						add(r, path, r.Field(path, schema))
+					}
+				}
+			})
+		}
+	}
+
+	return findings
+}
+
+// walk calls fn for every field in the schema. The root object and the standard
+// apiVersion, kind and metadata fields aren't considered.
+func walk(schema *v1beta1.JSONSchemaProps, fn func(path string, schema *v1beta1.JSONSchemaProps)) {
+	if schema == nil {
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		if name == "apiVersion" || name == "kind" || name == "metadata" {
+			continue
+		}
+
+		prop := schema.Properties[name]
+		walkField("."+name, &prop, fn)
+	}
+}
+
+func walkField(path string, schema *v1beta1.JSONSchemaProps, fn func(path string, schema *v1beta1.JSONSchemaProps)) {
+	fn(path, schema)
+
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		prop := schema.Properties[name]
+		walkField(path+"."+name, &prop, fn)
+	}
+
+	if schema.Items != nil && schema.Items.Schema != nil {
+		walkField(path+"[*]", schema.Items.Schema, fn)
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		walkField(path+".*", schema.AdditionalProperties.Schema, fn)
+	}
+}
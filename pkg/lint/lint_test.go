@@ -0,0 +1,103 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestLint(t *testing.T) {
+	linter, err := NewLinter(Config{})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join("testdata", "crd.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+	crds[0].Location = "crd.yaml"
+
+	findings := linter.Lint(crds)
+
+	type result struct {
+		Rule string
+		Path string
+	}
+
+	got := make([]result, 0, len(findings))
+	for _, f := range findings {
+		got = append(got, result{Rule: f.Rule, Path: f.Path})
+		assert.Equal(t, "crd.yaml", f.Location)
+	}
+
+	assert.ElementsMatch(t, []result{
+		{Rule: "missing-status-subresource"},
+		{Rule: "preserve-unknown-fields-spec", Path: ".spec"},
+		{Rule: "missing-description", Path: ".spec.list"},
+		{Rule: "unbounded-array", Path: ".spec.list"},
+		{Rule: "enum-type-mismatch", Path: ".spec.mode"},
+		{Rule: "invalid-pattern", Path: ".spec.name"},
+		{Rule: "invalid-default", Path: ".spec.replicas"},
+	}, got)
+}
+
+func TestLintConfig(t *testing.T) {
+	linter, err := NewLinter(Config{
+		Enable:  []string{"invalid-pattern", "invalid-default", "unbounded-array"},
+		Disable: []string{"unbounded-array"},
+		Rules: map[string]RuleConfig{
+			"invalid-pattern": {Severity: SeverityInfo},
+			"invalid-default": {Disabled: true},
+		},
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join("testdata", "crd.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+
+	findings := linter.Lint(crds)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "invalid-pattern", findings[0].Rule)
+	assert.Equal(t, SeverityInfo, findings[0].Severity)
+}
+
+func TestLintConfigUnknownRule(t *testing.T) {
+	_, err := NewLinter(Config{Disable: []string{"nope"}})
+	require.ErrorContains(t, err, `unknown rule "nope"`)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	linter, err := NewLinter(Config{Enable: []string{"invalid-pattern"}})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join("testdata", "crd.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+	crds[0].Location = "crd.yaml"
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteSARIF(buf, linter.Rules(), linter.Lint(crds)))
+
+	log := sarifLog{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 1)
+	assert.Equal(t, "invalid-pattern", log.Runs[0].Results[0].RuleID)
+	assert.Equal(t, "error", log.Runs[0].Results[0].Level)
+	assert.Equal(t, "crd.yaml", log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "group/v1/Kind.spec.name", log.Runs[0].Results[0].Locations[0].LogicalLocations[0].FullyQualifiedName)
+}
@@ -0,0 +1,226 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/convert"
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// DefaultRules contains all the rules the linter knows about.
+var DefaultRules = []Rule{
+	{
+		Name:        "missing-description",
+		Description: "Fields should have a description.",
+		Severity:    SeverityWarning,
+		Field:       missingDescription,
+	},
+	{
+		Name:        "missing-required",
+		Description: "Objects with properties should define which of them are required.",
+		Severity:    SeverityInfo,
+		Field:       missingRequired,
+	},
+	{
+		Name:        "unbounded-string",
+		Description: "Strings should define a maxLength to keep the cost of CEL rules bounded.",
+		Severity:    SeverityWarning,
+		Field:       unboundedString,
+	},
+	{
+		Name:        "unbounded-array",
+		Description: "Arrays should define maxItems to keep the cost of CEL rules bounded.",
+		Severity:    SeverityWarning,
+		Field:       unboundedArray,
+	},
+	{
+		Name:        "preserve-unknown-fields-spec",
+		Description: "The spec should not preserve unknown fields, because that disables pruning and validation.",
+		Severity:    SeverityWarning,
+		Field:       preserveUnknownFieldsSpec,
+	},
+	{
+		Name:        "enum-type-mismatch",
+		Description: "Enum values must match the type of the field.",
+		Severity:    SeverityError,
+		Field:       enumTypeMismatch,
+	},
+	{
+		Name:        "invalid-default",
+		Description: "Default values must be valid according to the schema of the field.",
+		Severity:    SeverityError,
+		Field:       invalidDefault,
+	},
+	{
+		Name:        "invalid-pattern",
+		Description: "Patterns must be valid regular expressions.",
+		Severity:    SeverityError,
+		Field:       invalidPattern,
+	},
+	{
+		Name:        "missing-status-subresource",
+		Description: "Versions that have a status should enable the status subresource.",
+		Severity:    SeverityWarning,
+		Version:     missingStatusSubresource,
+	},
+}
+
+func missingDescription(_ string, schema *v1beta1.JSONSchemaProps) []string {
+	if schema.Description != "" {
+		return nil
+	}
+
+	return []string{"field has no description"}
+}
+
+func missingRequired(_ string, schema *v1beta1.JSONSchemaProps) []string {
+	if len(schema.Properties) == 0 || len(schema.Required) > 0 {
+		return nil
+	}
+
+	return []string{"object has properties but no required list"}
+}
+
+func unboundedString(_ string, schema *v1beta1.JSONSchemaProps) []string {
+	if schema.Type != "string" || schema.MaxLength != nil || len(schema.Enum) > 0 {
+		return nil
+	}
+
+	return []string{"string has no maxLength"}
+}
+
+func unboundedArray(_ string, schema *v1beta1.JSONSchemaProps) []string {
+	if schema.Type != "array" || schema.MaxItems != nil {
+		return nil
+	}
+
+	return []string{"array has no maxItems"}
+}
+
+func preserveUnknownFieldsSpec(path string, schema *v1beta1.JSONSchemaProps) []string {
+	if path != ".spec" || schema.XPreserveUnknownFields == nil || !*schema.XPreserveUnknownFields {
+		return nil
+	}
+
+	return []string{"x-kubernetes-preserve-unknown-fields is set on the spec"}
+}
+
+func enumTypeMismatch(_ string, schema *v1beta1.JSONSchemaProps) []string {
+	if schema.Type == "" {
+		return nil
+	}
+
+	var result []string
+	for _, e := range schema.Enum {
+		var value any
+		if err := json.Unmarshal(e.Raw, &value); err != nil {
+			result = append(result, fmt.Sprintf("enum value %s is not valid JSON", string(e.Raw)))
+
+			continue
+		}
+
+		if value == nil && schema.Nullable {
+			continue
+		}
+
+		if !matchesType(value, schema.Type) {
+			result = append(result, fmt.Sprintf("enum value %s is not of type %s", string(e.Raw), schema.Type))
+		}
+	}
+
+	return result
+}
+
+func matchesType(value any, t string) bool {
+	switch t {
+	case "string":
+		_, ok := value.(string)
+
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(float64)
+
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+
+		return ok
+	case "array":
+		_, ok := value.([]any)
+
+		return ok
+	}
+
+	return true
+}
+
+func invalidDefault(_ string, schema *v1beta1.JSONSchemaProps) []string {
+	if schema.Default == nil {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(schema.Default.Raw, &value); err != nil {
+		return []string{fmt.Sprintf("default %s is not valid JSON", string(schema.Default.Raw))}
+	}
+
+	internal, err := convert.ToInternalSchema(schema)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to convert schema to validate default: %s", err)}
+	}
+
+	validator, _, err := validation.NewSchemaValidator(internal)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to create schema validator: %s", err)}
+	}
+
+	result := validator.Validate(value)
+	if result.IsValid() {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("default %s is invalid: %s", string(schema.Default.Raw), errors.Join(result.Errors...))}
+}
+
+func invalidPattern(_ string, schema *v1beta1.JSONSchemaProps) []string {
+	if schema.Pattern == "" {
+		return nil
+	}
+
+	if _, err := regexp.Compile(schema.Pattern); err != nil {
+		return []string{fmt.Sprintf("pattern %q does not compile: %s", schema.Pattern, err)}
+	}
+
+	return nil
+}
+
+func missingStatusSubresource(_ *pkg.SchemaType, version *pkg.CRDVersion) []string {
+	if version.Schema == nil {
+		return nil
+	}
+
+	if _, ok := version.Schema.Properties["status"]; !ok {
+		return nil
+	}
+
+	if version.Subresources != nil && version.Subresources.Status {
+		return nil
+	}
+
+	return []string{"version defines a status but the status subresource is not enabled"}
+}
@@ -4,32 +4,71 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/yaml"
-)
 
-const maxBufferSize = 2048
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
 
 // Validate takes a source CRD and a sample file and validates its contents against the CRD definition.
+// Every document in the sample file is validated.
 func Validate(sourceCRD []byte, sampleFile []byte) error {
-	reader := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(sampleFile), maxBufferSize)
-	obj := &unstructured.Unstructured{}
-	err := reader.Decode(obj)
-	if err != nil {
-		return fmt.Errorf("failed to decode sample file: %w", err)
-	}
-
 	crd := &apiextensions.CustomResourceDefinition{}
 	if err := yaml.Unmarshal(sourceCRD, crd); err != nil {
 		return errors.New("failed to unmarshal into custom resource definition")
 	}
 
+	objects, err := decodeSamples(sampleFile)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if err := validateObject(crd, obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeSamples decodes all documents of a sample file and skips empty ones.
+func decodeSamples(sampleFile []byte) ([]*unstructured.Unstructured, error) {
+	reader := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(sampleFile), pkg.DecoderBufferSize)
+
+	var objects []*unstructured.Unstructured
+	for {
+		content := map[string]any{}
+		if err := reader.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("failed to decode sample file: %w", err)
+		}
+
+		if len(content) == 0 {
+			continue
+		}
+
+		objects = append(objects, &unstructured.Unstructured{Object: content})
+	}
+
+	if len(objects) == 0 {
+		return nil, errors.New("failed to decode sample file: no objects found")
+	}
+
+	return objects, nil
+}
+
+func validateObject(crd *apiextensions.CustomResourceDefinition, obj *unstructured.Unstructured) error {
 	if crd.Spec.Validation != nil && len(crd.Spec.Versions) == 0 {
-		return ValidateCRDValidation(crd, sampleFile)
+		return validate(crd.Spec.Validation.OpenAPIV3Schema, obj, crd.Spec.Names.Kind, crd.Name)
 	}
 
 	availableVersions := make([]string, 0, len(crd.Spec.Versions))
@@ -56,15 +95,20 @@ func Validate(sourceCRD []byte, sampleFile []byte) error {
 	)
 }
 
+// ValidateCRDValidation validates every document of the sample file against the validation of a CRD without versions.
 func ValidateCRDValidation(crd *apiextensions.CustomResourceDefinition, sampleFile []byte) error {
-	reader := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(sampleFile), maxBufferSize)
-	obj := &unstructured.Unstructured{}
-	err := reader.Decode(obj)
+	objects, err := decodeSamples(sampleFile)
 	if err != nil {
-		return fmt.Errorf("failed to decode sample file: %w", err)
+		return err
 	}
 
-	return validate(crd.Spec.Validation.OpenAPIV3Schema, obj, crd.Spec.Names.Kind, crd.Name)
+	for _, obj := range objects {
+		if err := validate(crd.Spec.Validation.OpenAPIV3Schema, obj, crd.Spec.Names.Kind, crd.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func validate(props *apiextensions.JSONSchemaProps, obj *unstructured.Unstructured, kind, name string) error {
@@ -0,0 +1,62 @@
+package matches
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sample  string
+		wantErr string
+	}{
+		{
+			name:   "single document",
+			sample: "apiVersion: example.com/v1\nkind: Example\nspec:\n  replicas: 1\n",
+		},
+		{
+			name:   "every document is valid",
+			sample: "apiVersion: example.com/v1\nkind: Example\nspec:\n  replicas: 1\n---\napiVersion: example.com/v1\nkind: Example\nspec:\n  names: [a]\n",
+		},
+		{
+			name:    "a later document is invalid",
+			sample:  "apiVersion: example.com/v1\nkind: Example\nspec:\n  replicas: 1\n---\napiVersion: example.com/v1\nkind: Example\nspec:\n  replicas: one\n",
+			wantErr: "spec.replicas",
+		},
+		{
+			name:   "empty documents are skipped",
+			sample: "---\n# only a comment\n---\napiVersion: example.com/v1\nkind: Example\nspec:\n  replicas: 1\n---\n",
+		},
+		{
+			name:    "only empty documents",
+			sample:  "---\n# only a comment\n---\n",
+			wantErr: "no objects found",
+		},
+		{
+			name:    "version without a matching CRD version",
+			sample:  "apiVersion: example.com/v2\nkind: Example\nspec:\n  replicas: 1\n",
+			wantErr: "version of the snapshot v2 not found amongst the available testing versions of the CRD v1",
+		},
+	}
+
+	crd, err := os.ReadFile(filepath.Join("testdata", "crd.yaml"))
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(crd, []byte(tt.sample))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
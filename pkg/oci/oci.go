@@ -0,0 +1,207 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	orascredentials "oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/helm"
+)
+
+const (
+	// Scheme is the prefix of OCI references.
+	Scheme = "oci://"
+
+	helmChartMediaType      = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// Options configure how the registry is accessed. If no credentials are set, the ones stored for the
+// registry by docker login, in the docker config file or a credential helper configured in it, are used.
+type Options struct {
+	Username string
+	Password string
+	// Token is a bearer token sent to registries that use token authentication.
+	Token string
+	// PlainHTTP connects to the registry with HTTP instead of HTTPS.
+	PlainHTTP bool
+}
+
+// Manifests pulls the artifact at reference and returns the files in it that contain CRDs. Helm charts
+// are rendered with their default values. Charts whose templates can't be rendered contribute the files
+// of their crds directories, and their *helm.RenderError is returned together with the manifests. Other layers can be plain files or tar archives, which may be
+// compressed with gzip.
+func Manifests(ctx context.Context, reference string, opts Options) ([]helm.Manifest, error) {
+	repo, err := remote.NewRepository(strings.TrimPrefix(reference, Scheme))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI reference %s: %w", reference, err)
+	}
+
+	if repo.Reference.Reference == "" {
+		return nil, fmt.Errorf("OCI reference %s must contain a tag or a digest", reference)
+	}
+
+	credential, err := credentialFunc(repo.Reference.Registry, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.PlainHTTP = opts.PlainHTTP
+	repo.Client = &auth.Client{
+		Client:     retry.DefaultClient,
+		Cache:      auth.NewCache(),
+		Credential: credential,
+	}
+
+	desc, rc, err := repo.FetchReference(ctx, repo.Reference.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest of %s: %w", reference, err)
+	}
+	defer rc.Close()
+
+	if desc.MediaType != ocispec.MediaTypeImageManifest && desc.MediaType != dockerManifestMediaType {
+		return nil, fmt.Errorf("unsupported manifest media type %s of %s", desc.MediaType, reference)
+	}
+
+	data, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest of %s: %w", reference, err)
+	}
+
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest of %s: %w", reference, err)
+	}
+
+	var (
+		manifests    []helm.Manifest
+		renderErrors []error
+	)
+	for _, layer := range manifest.Layers {
+		blob, err := content.FetchAll(ctx, repo, layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch layer %s of %s: %w", layer.Digest, reference, err)
+		}
+
+		layerManifests, err := layerManifests(reference, layer, blob)
+		var renderErr *helm.RenderError
+		switch {
+		case errors.As(err, &renderErr):
+			renderErrors = append(renderErrors, err)
+		case err != nil:
+			return nil, fmt.Errorf("failed to read layer %s of %s: %w", layer.Digest, reference, err)
+		}
+
+		manifests = append(manifests, layerManifests...)
+	}
+
+	return manifests, errors.Join(renderErrors...)
+}
+
+// credentialFunc returns the credentials of opts or, if none are set, the ones stored in the docker config.
+func credentialFunc(registry string, opts Options) (auth.CredentialFunc, error) {
+	if opts.Username != "" || opts.Password != "" || opts.Token != "" {
+		return auth.StaticCredential(registry, auth.Credential{
+			Username:    opts.Username,
+			Password:    opts.Password,
+			AccessToken: opts.Token,
+		}), nil
+	}
+
+	store, err := orascredentials.NewStoreFromDocker(orascredentials.StoreOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load docker config: %w", err)
+	}
+
+	return orascredentials.Credential(store), nil
+}
+
+// layerManifests returns the files of a layer that contain CRDs.
+func layerManifests(reference string, layer ocispec.Descriptor, blob []byte) ([]helm.Manifest, error) {
+	if layer.MediaType == helmChartMediaType {
+		ch, err := loader.LoadArchive(bytes.NewReader(blob))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chart: %w", err)
+		}
+
+		// a *helm.RenderError is returned with the manifests of the crds directories.
+		manifests, err := helm.Manifests(ch)
+		if manifests == nil {
+			return nil, err
+		}
+
+		for i := range manifests {
+			manifests[i].Name = reference + "/" + manifests[i].Name
+		}
+
+		return manifests, err
+	}
+
+	name := reference
+	if title := layer.Annotations[ocispec.AnnotationTitle]; title != "" {
+		name += "/" + title
+	}
+
+	if gz, err := gzip.NewReader(bytes.NewReader(blob)); err == nil {
+		blob, err = io.ReadAll(gz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress layer: %w", err)
+		}
+	}
+
+	tr := tar.NewReader(bytes.NewReader(blob))
+	if _, err := tr.Next(); err != nil {
+		// not an archive, so the layer is a single file.
+		if !pkg.ContainsCRD(blob) {
+			return nil, nil
+		}
+
+		return []helm.Manifest{{Name: name, Content: blob}}, nil
+	}
+
+	return archiveManifests(reference, blob)
+}
+
+// archiveManifests returns the files of a tar archive that contain CRDs.
+func archiveManifests(reference string, blob []byte) ([]helm.Manifest, error) {
+	var manifests []helm.Manifest
+
+	tr := tar.NewReader(bytes.NewReader(blob))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		file, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", header.Name, err)
+		}
+
+		if pkg.ContainsCRD(file) {
+			manifests = append(manifests, helm.Manifest{Name: reference + "/" + strings.TrimPrefix(header.Name, "./"), Content: file})
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: bars.example.com
+spec:
+  group: example.com
+  names:
+    kind: Bar
+    plural: bars
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+
+// registry serves a single manifest and its blobs like an OCI registry that requires basic auth.
+type registry struct {
+	manifest []byte
+	blobs    map[digest.Digest][]byte
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if user, password, ok := req.BasicAuth(); !ok || user != "user" || password != "password" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	var body []byte
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/v2/crds/manifests/"):
+		body = r.manifest
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(body).String())
+	case strings.HasPrefix(req.URL.Path, "/v2/crds/blobs/"):
+		blob, ok := r.blobs[digest.Digest(strings.TrimPrefix(req.URL.Path, "/v2/crds/blobs/"))]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		body = blob
+	default:
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if req.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+func newRegistry(t *testing.T, layers map[string][]byte, mediaTypes map[string]string) string {
+	t.Helper()
+
+	r := &registry{blobs: map[digest.Digest][]byte{}}
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+	}
+	manifest.SchemaVersion = 2
+	r.blobs[ocispec.DescriptorEmptyJSON.Digest] = ocispec.DescriptorEmptyJSON.Data
+
+	for _, title := range []string{"crds.yaml", "bundle.tar.gz", "chart.tgz"} {
+		blob, ok := layers[title]
+		if !ok {
+			continue
+		}
+
+		desc := ocispec.Descriptor{
+			MediaType:   mediaTypes[title],
+			Digest:      digest.FromBytes(blob),
+			Size:        int64(len(blob)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: title},
+		}
+		r.blobs[desc.Digest] = blob
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+
+	var err error
+	r.manifest, err = json.Marshal(manifest)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return Scheme + strings.TrimPrefix(server.URL, "http://") + "/crds:v1"
+}
+
+func bundle(t *testing.T) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{"config/crd/bars.yaml": crd, "README.md": "no CRDs"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func chart(t *testing.T) []byte {
+	t.Helper()
+
+	ch, err := loader.Load("../helm/testdata/chart")
+	require.NoError(t, err)
+
+	archive, err := chartutil.Save(ch, t.TempDir())
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(archive)
+	require.NoError(t, err)
+
+	return content
+}
+
+func TestManifests(t *testing.T) {
+	reference := newRegistry(t, map[string][]byte{
+		"crds.yaml":     []byte(crd),
+		"bundle.tar.gz": bundle(t),
+		"chart.tgz":     chart(t),
+	}, map[string]string{
+		"crds.yaml":     "application/yaml",
+		"bundle.tar.gz": ocispec.MediaTypeImageLayerGzip,
+		"chart.tgz":     helmChartMediaType,
+	})
+
+	manifests, err := Manifests(context.Background(), reference, Options{Username: "user", Password: "password", PlainHTTP: true})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(manifests))
+	for _, m := range manifests {
+		names = append(names, strings.TrimPrefix(m.Name, reference+"/"))
+	}
+
+	assert.Equal(t, []string{
+		"crds.yaml",
+		"config/crd/bars.yaml",
+		"chart/crds/bars.yaml",
+		"chart/charts/sub/crds/quxes.yaml",
+		"chart/charts/sub/templates/crd.yaml",
+		"chart/templates/crds.yaml",
+	}, names)
+	assert.Equal(t, crd, string(manifests[0].Content))
+}
+
+func TestManifestsUnauthorized(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	reference := newRegistry(t, map[string][]byte{"crds.yaml": []byte(crd)}, map[string]string{"crds.yaml": "application/yaml"})
+
+	_, err := Manifests(context.Background(), reference, Options{PlainHTTP: true})
+	require.Error(t, err)
+}
+
+func TestManifestsWithDockerConfig(t *testing.T) {
+	reference := newRegistry(t, map[string][]byte{"crds.yaml": []byte(crd)}, map[string]string{"crds.yaml": "application/yaml"})
+	registry, _, _ := strings.Cut(strings.TrimPrefix(reference, Scheme), "/")
+
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	config := `{"auths": {"` + registry + `": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("user:password")) + `"}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600))
+
+	manifests, err := Manifests(context.Background(), reference, Options{PlainHTTP: true})
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+}
+
+func TestManifestsWithoutTag(t *testing.T) {
+	_, err := Manifests(context.Background(), "oci://localhost:5000/crds", Options{})
+	require.ErrorContains(t, err, "must contain a tag or a digest")
+}
@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+const (
+	refPrefix = "#/components/schemas/"
+
+	namespaced = "Namespaced"
+	cluster    = "Cluster"
+)
+
+type groupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+type operation struct {
+	Action string            `json:"x-kubernetes-action"`
+	GVK    *groupVersionKind `json:"x-kubernetes-group-version-kind"`
+}
+
+type pathItem struct {
+	Get  *operation `json:"get"`
+	Post *operation `json:"post"`
+}
+
+type schema struct {
+	GVKs []groupVersionKind `json:"x-kubernetes-group-version-kind"`
+}
+
+// document is the part of an OpenAPI v3 document served by the apiserver at /openapi/v3 that is
+// needed to find the schemas of the served resources.
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	} `json:"components"`
+}
+
+// resource is a kind served at a path of the document.
+type resource struct {
+	plural string
+	scope  string
+}
+
+// IsDocument returns true if content is an OpenAPI v3 document containing schemas.
+func IsDocument(content []byte) bool {
+	doc := document{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(doc.OpenAPI, "3.") && len(doc.Components.Schemas) > 0
+}
+
+// SchemaTypes returns a schema type for every kind served by the paths of an OpenAPI v3 document
+// like the ones the apiserver serves at /openapi/v3. References to components/schemas are resolved.
+func SchemaTypes(content []byte) ([]*pkg.SchemaType, error) {
+	doc := document{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI document: %w", err)
+	}
+
+	resources := servedResources(doc)
+
+	r := &pkg.RefResolver{
+		Prefix:        refPrefix,
+		Definitions:   make(v1beta1.JSONSchemaDefinitions, len(doc.Components.Schemas)),
+		IgnoreMissing: true,
+		Normalize:     normalize,
+	}
+	gvkSchemas := map[groupVersionKind]string{}
+	for name, raw := range doc.Components.Schemas {
+		props := v1beta1.JSONSchemaProps{}
+		if err := json.Unmarshal(raw, &props); err != nil {
+			return nil, fmt.Errorf("failed to decode schema %s: %w", name, err)
+		}
+		r.Definitions[name] = props
+
+		s := schema{}
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("failed to decode schema %s: %w", name, err)
+		}
+
+		for _, gvk := range s.GVKs {
+			if _, ok := resources[gvk]; ok {
+				gvkSchemas[gvk] = name
+			}
+		}
+	}
+
+	gvks := make([]groupVersionKind, 0, len(gvkSchemas))
+	for gvk := range gvkSchemas {
+		gvks = append(gvks, gvk)
+	}
+
+	slices.SortFunc(gvks, func(a, b groupVersionKind) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Version, b.Version))
+	})
+
+	var schemaTypes []*pkg.SchemaType
+	for _, gvk := range gvks {
+		// the schema is resolved as a reference to itself, so references back to it are cyclic.
+		ref := refPrefix + gvkSchemas[gvk]
+		resolved, err := r.Resolve(&v1beta1.JSONSchemaProps{Ref: &ref})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve schema of %s: %w", gvk.Kind, err)
+		}
+
+		if len(schemaTypes) == 0 || schemaTypes[len(schemaTypes)-1].Group != gvk.Group || schemaTypes[len(schemaTypes)-1].Kind != gvk.Kind {
+			schemaTypes = append(schemaTypes, &pkg.SchemaType{
+				Group:  gvk.Group,
+				Kind:   gvk.Kind,
+				Plural: resources[gvk].plural,
+				Scope:  resources[gvk].scope,
+			})
+		}
+
+		schemaType := schemaTypes[len(schemaTypes)-1]
+		schemaType.Versions = append(schemaType.Versions, &pkg.CRDVersion{Name: gvk.Version, Schema: resolved})
+	}
+
+	return schemaTypes, nil
+}
+
+// Merge merges schema types of the same group and kind, which are served in different documents
+// for different versions.
+func Merge(schemaTypes []*pkg.SchemaType) []*pkg.SchemaType {
+	var result []*pkg.SchemaType
+	for _, schemaType := range schemaTypes {
+		i := slices.IndexFunc(result, func(s *pkg.SchemaType) bool {
+			return s.Group == schemaType.Group && s.Kind == schemaType.Kind
+		})
+		if i == -1 {
+			result = append(result, schemaType)
+
+			continue
+		}
+
+		for _, version := range schemaType.Versions {
+			if !slices.ContainsFunc(result[i].Versions, func(v *pkg.CRDVersion) bool { return v.Name == version.Name }) {
+				result[i].Versions = append(result[i].Versions, version)
+			}
+		}
+	}
+
+	return result
+}
+
+// servedResources returns the kinds that can be created or listed at the paths of the document. Kinds
+// that are only referenced, like DeleteOptions, and subresources, like Scale, aren't served at their own path.
+func servedResources(doc document) map[groupVersionKind]resource {
+	resources := map[groupVersionKind]resource{}
+	for path, item := range doc.Paths {
+		segments := strings.Split(path, "/")
+		plural := segments[len(segments)-1]
+		if strings.HasPrefix(plural, "{") {
+			continue
+		}
+
+		for _, op := range []*operation{item.Post, item.Get} {
+			if op == nil || op.GVK == nil || (op.Action != "post" && op.Action != "list") {
+				continue
+			}
+
+			r := resources[*op.GVK]
+			r.plural = plural
+			if r.scope == "" {
+				r.scope = cluster
+			}
+
+			if strings.Contains(path, "/namespaces/{namespace}/") {
+				r.scope = namespaced
+			}
+
+			resources[*op.GVK] = r
+		}
+	}
+
+	return resources
+}
+
+// normalize unwraps the allOf the apiserver wraps references in to add a description and a default,
+// so the reference is resolved. The default is dropped, because it's {} for every struct, even if the
+// referenced type is a string like Time. It also marks int-or-string formats as such.
+func normalize(props v1beta1.JSONSchemaProps) v1beta1.JSONSchemaProps {
+	if len(props.AllOf) == 1 && props.AllOf[0].Ref != nil && len(props.Properties) == 0 {
+		ref := props.AllOf[0]
+		ref.Description = cmp.Or(props.Description, ref.Description)
+
+		return ref
+	}
+
+	if props.Format == "int-or-string" {
+		props.XIntOrString = true
+	}
+
+	return props
+}
@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestSchemaTypes(t *testing.T) {
+	content, err := os.ReadFile("testdata/apis__example.com__v1_openapi.json")
+	require.NoError(t, err)
+	require.True(t, IsDocument(content))
+
+	schemaTypes, err := SchemaTypes(content)
+	require.NoError(t, err)
+	require.Len(t, schemaTypes, 2)
+
+	gadget, widget := schemaTypes[0], schemaTypes[1]
+	assert.Equal(t, "Gadget", gadget.Kind)
+	assert.Equal(t, "gadgets", gadget.Plural)
+	assert.Equal(t, "Cluster", gadget.Scope)
+
+	assert.Equal(t, "example.com", widget.Group)
+	assert.Equal(t, "Widget", widget.Kind)
+	assert.Equal(t, "widgets", widget.Plural)
+	assert.Equal(t, "Namespaced", widget.Scope)
+	require.Len(t, widget.Versions, 1)
+	assert.Equal(t, "v1", widget.Versions[0].Name)
+
+	schema := widget.Versions[0].Schema
+	metadata := schema.Properties["metadata"]
+	assert.Equal(t, "Standard object's metadata.", metadata.Description)
+	assert.Contains(t, metadata.Properties, "name")
+
+	spec := schema.Properties["spec"]
+	assert.Equal(t, "WidgetSpec is the spec of a widget.", spec.Description)
+	assert.Equal(t, []string{"replicas"}, spec.Required)
+	assert.True(t, spec.Properties["port"].XIntOrString)
+	assert.Equal(t, "string", spec.Properties["labels"].AdditionalProperties.Schema.Type)
+
+	// unknown references are replaced by an object with unknown fields.
+	missing := spec.Properties["missing"]
+	assert.Equal(t, "object", missing.Type)
+	require.NotNil(t, missing.XPreserveUnknownFields)
+	assert.True(t, *missing.XPreserveUnknownFields)
+
+	// the cyclic reference of parts is resolved once.
+	part := spec.Properties["parts"].Items.Schema
+	assert.Equal(t, "Part can contain other parts.", part.Description)
+	assert.Contains(t, part.Properties, "name")
+	nested := part.Properties["parts"].Items.Schema
+	assert.Empty(t, nested.Properties)
+	require.NotNil(t, nested.XPreserveUnknownFields)
+	assert.True(t, *nested.XPreserveUnknownFields)
+
+	buf := &bytes.Buffer{}
+	parser := pkg.NewParser(widget.Group, widget.Kind, false, false, true)
+	require.NoError(t, parser.ParseProperties("v1", buf, schema.Properties, schema.Required))
+	assert.Contains(t, buf.String(), "  replicas: 1\n")
+	assert.Contains(t, buf.String(), "kind: Widget\n")
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge([]*pkg.SchemaType{
+		{Group: "apps", Kind: "Deployment", Versions: []*pkg.CRDVersion{{Name: "v1"}}},
+		{Group: "apps", Kind: "StatefulSet", Versions: []*pkg.CRDVersion{{Name: "v1"}}},
+		{Group: "apps", Kind: "Deployment", Versions: []*pkg.CRDVersion{{Name: "v1beta1"}, {Name: "v1"}}},
+	})
+
+	require.Len(t, merged, 2)
+	assert.Equal(t, "Deployment", merged[0].Kind)
+	require.Len(t, merged[0].Versions, 2)
+	assert.Equal(t, "v1beta1", merged[0].Versions[1].Name)
+}
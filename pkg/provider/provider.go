@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Type is the type of the API of a provider.
+type Type string
+
+const (
+	GitHub Type = "github"
+	GitLab Type = "gitlab"
+)
+
+// Provider hosts git repositories and serves the files in them.
+type Provider struct {
+	// Host is the host of the web pages of the provider, like github.com.
+	Host string
+	// RawURL is the base URL the raw content of files is served from.
+	RawURL string
+	// APIURL is the base URL of the API, which serves files of private repositories.
+	APIURL string
+	Type   Type
+}
+
+// Providers are the providers whose URLs are recognised by default.
+var Providers = []Provider{
+	{Host: "github.com", RawURL: "https://raw.githubusercontent.com", APIURL: "https://api.github.com", Type: GitHub},
+	{Host: "gitlab.com", RawURL: "https://gitlab.com", APIURL: "https://gitlab.com/api/v4", Type: GitLab},
+}
+
+// Location is a file or a directory at a ref of a repository, parsed from the URL of its web page.
+type Location struct {
+	Provider Provider
+	// Repository is the path of the repository, like owner/repository.
+	Repository string
+	Ref        string
+	Path       string
+	// Tree is true if the location is a directory.
+	Tree bool
+
+	base string
+}
+
+// Parse parses a blob or tree URL like https://github.com/owner/repository/blob/main/crd.yaml or
+// https://gitlab.com/group/project/-/tree/main/config of one of the providers. The first segment after blob or
+// tree is taken as the ref, so refs containing slashes aren't supported.
+func Parse(rawURL string, providers []Provider) (*Location, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, false
+	}
+
+	for _, p := range providers {
+		if u.Host != p.Host {
+			continue
+		}
+
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if loc, ok := parseSegments(p, segments); ok {
+			loc.base = u.Scheme + "://" + u.Host
+
+			return loc, true
+		}
+	}
+
+	return nil, false
+}
+
+func parseSegments(p Provider, segments []string) (*Location, bool) {
+	var repository, rest []string
+
+	switch p.Type {
+	case GitHub:
+		if len(segments) < 4 { //nolint:mnd // owner, repository, blob or tree, ref
+			return nil, false
+		}
+
+		repository, rest = segments[:2], segments[2:]
+	case GitLab:
+		// groups can be nested, so the repository ends before the - separator.
+		i := slices.Index(segments, "-")
+		if i < 2 || len(segments) < i+3 {
+			return nil, false
+		}
+
+		repository, rest = segments[:i], segments[i+1:]
+	default:
+		return nil, false
+	}
+
+	if rest[0] != "blob" && rest[0] != "tree" {
+		return nil, false
+	}
+
+	loc := &Location{
+		Provider:   p,
+		Repository: strings.TrimSuffix(strings.Join(repository, "/"), ".git"),
+		Ref:        rest[1],
+		Path:       strings.Join(rest[2:], "/"),
+		Tree:       rest[0] == "tree",
+	}
+
+	if !loc.Tree && loc.Path == "" {
+		return nil, false
+	}
+
+	return loc, true
+}
+
+// CloneURL returns the URL the repository can be cloned from.
+func (l *Location) CloneURL() string {
+	return l.base + "/" + l.Repository + ".git"
+}
+
+// TokenUsername returns the username that authenticates cloning over HTTPS with a token as the password.
+func (l *Location) TokenUsername() string {
+	switch l.Provider.Type {
+	case GitLab:
+		return "oauth2"
+	default:
+		return "x-access-token"
+	}
+}
+
+// RawURL returns the URL of the raw content of a file of a public repository.
+func (l *Location) RawURL() string {
+	switch l.Provider.Type {
+	case GitLab:
+		return l.Provider.RawURL + "/" + l.Repository + "/-/raw/" + l.Ref + "/" + l.Path
+	default:
+		return l.Provider.RawURL + "/" + l.Repository + "/" + l.Ref + "/" + l.Path
+	}
+}
+
+// APIURL returns the URL at which the API serves the raw content of a file and the headers the request needs.
+// Unlike the raw URL, it serves files of private repositories to requests authenticated with a token.
+func (l *Location) APIURL() (string, map[string]string) {
+	switch l.Provider.Type {
+	case GitLab:
+		return l.Provider.APIURL + "/projects/" + url.PathEscape(l.Repository) + "/repository/files/" +
+			url.PathEscape(l.Path) + "/raw?ref=" + url.QueryEscape(l.Ref), nil
+	default:
+		return l.Provider.APIURL + "/repos/" + l.Repository + "/contents/" + l.Path + "?ref=" + url.QueryEscape(l.Ref),
+			map[string]string{"Accept": "application/vnd.github.raw"}
+	}
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		ok        bool
+		tree      bool
+		clone     string
+		raw       string
+		api       string
+		username  string
+		accept    string
+		reference string
+		path      string
+	}{
+		{
+			name:      "github blob",
+			url:       "https://github.com/Skarlso/crd-bootstrap/blob/main/config/crd/bases/bootstrap.yaml",
+			ok:        true,
+			clone:     "https://github.com/Skarlso/crd-bootstrap.git",
+			raw:       "https://raw.githubusercontent.com/Skarlso/crd-bootstrap/main/config/crd/bases/bootstrap.yaml",
+			api:       "https://api.github.com/repos/Skarlso/crd-bootstrap/contents/config/crd/bases/bootstrap.yaml?ref=main",
+			username:  "x-access-token",
+			accept:    "application/vnd.github.raw",
+			reference: "main",
+			path:      "config/crd/bases/bootstrap.yaml",
+		},
+		{
+			name:      "github tree",
+			url:       "https://github.com/Skarlso/crd-bootstrap/tree/v0.1.0/config/crd",
+			ok:        true,
+			tree:      true,
+			clone:     "https://github.com/Skarlso/crd-bootstrap.git",
+			username:  "x-access-token",
+			reference: "v0.1.0",
+			path:      "config/crd",
+		},
+		{
+			name:      "gitlab blob in nested group",
+			url:       "https://gitlab.com/group/sub/project/-/blob/main/crds/crd.yaml",
+			ok:        true,
+			clone:     "https://gitlab.com/group/sub/project.git",
+			raw:       "https://gitlab.com/group/sub/project/-/raw/main/crds/crd.yaml",
+			api:       "https://gitlab.com/api/v4/projects/group%2Fsub%2Fproject/repository/files/crds%2Fcrd.yaml/raw?ref=main",
+			username:  "oauth2",
+			reference: "main",
+			path:      "crds/crd.yaml",
+		},
+		{
+			name:      "gitlab tree of root",
+			url:       "https://gitlab.com/group/project/-/tree/main",
+			ok:        true,
+			tree:      true,
+			clone:     "https://gitlab.com/group/project.git",
+			username:  "oauth2",
+			reference: "main",
+		},
+		{name: "raw content", url: "https://raw.githubusercontent.com/Skarlso/crd-bootstrap/main/crd.yaml"},
+		{name: "repository", url: "https://github.com/Skarlso/crd-bootstrap"},
+		{name: "blob without path", url: "https://github.com/Skarlso/crd-bootstrap/blob/main"},
+		{name: "other page", url: "https://github.com/Skarlso/crd-bootstrap/pulls/1"},
+		{name: "other host", url: "https://example.com/Skarlso/crd-bootstrap/blob/main/crd.yaml"},
+		{name: "ssh", url: "git@github.com:Skarlso/crd-bootstrap.git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, ok := Parse(tt.url, Providers)
+			require.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+
+			assert.Equal(t, tt.tree, loc.Tree)
+			assert.Equal(t, tt.reference, loc.Ref)
+			assert.Equal(t, tt.path, loc.Path)
+			assert.Equal(t, tt.clone, loc.CloneURL())
+			assert.Equal(t, tt.username, loc.TokenUsername())
+			if tt.tree {
+				return
+			}
+
+			assert.Equal(t, tt.raw, loc.RawURL())
+			api, headers := loc.APIURL()
+			assert.Equal(t, tt.api, api)
+			assert.Equal(t, tt.accept, headers["Accept"])
+		})
+	}
+}
+
+func TestFetchFromStandIn(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/raw/owner/repository/main/crd.yaml":
+			_, _ = w.Write([]byte("kind: Public"))
+		case r.URL.Path == "/api/repos/owner/repository/contents/crd.yaml" && r.URL.Query().Get("ref") == "main":
+			if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("Accept") != "application/vnd.github.raw" {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte("kind: Private"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	standIn := []Provider{{Host: host, RawURL: server.URL + "/raw", APIURL: server.URL + "/api", Type: GitHub}}
+
+	loc, ok := Parse(server.URL+"/owner/repository/blob/main/crd.yaml", standIn)
+	require.True(t, ok)
+	assert.Equal(t, server.URL+"/owner/repository.git", loc.CloneURL())
+
+	content, err := fetcher.NewFetcher(server.Client(), "", "", "").Fetch(loc.RawURL())
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Public", string(content))
+
+	api, headers := loc.APIURL()
+	content, err = fetcher.NewFetcher(server.Client(), "", "", "token").WithHeaders(headers).Fetch(api)
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Private", string(content))
+}
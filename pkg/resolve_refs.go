@@ -0,0 +1,230 @@
+package pkg
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+const (
+	definitionsPrefix = "#/definitions/"
+	// MaxRefDepth is the maximum number of nested references that are resolved. Deeper references
+	// are treated like cyclic references.
+	MaxRefDepth = 32
+)
+
+// RefResolver replaces references in schemas by the schemas they point to. Cyclic references and
+// references nested deeper than MaxRefDepth are replaced by an object that preserves unknown fields.
+type RefResolver struct {
+	// Prefix is the prefix of the references that are resolved, like #/definitions/. Other references,
+	// like the ones to other documents, are left untouched.
+	Prefix string
+	// Definitions are the schemas references can point to. The definitions of a schema are added to
+	// them for the schema and all schemas below it.
+	Definitions v1beta1.JSONSchemaDefinitions
+	// IgnoreMissing replaces references to missing definitions by an object that preserves unknown
+	// fields instead of failing.
+	IgnoreMissing bool
+	// Normalize is called for every schema before its references are resolved if it's set.
+	Normalize func(props v1beta1.JSONSchemaProps) v1beta1.JSONSchemaProps
+}
+
+// ResolveRefs inlines the local references to #/definitions/... in the schemas of all versions and
+// the validation. References to other documents are left untouched.
+func (s *SchemaType) ResolveRefs() error {
+	for _, version := range s.Versions {
+		schema, err := ResolveRefs(version.Schema)
+		if err != nil {
+			return fmt.Errorf("failed to resolve references of version %s: %w", version.Name, err)
+		}
+
+		version.Schema = schema
+	}
+
+	if s.Validation != nil {
+		schema, err := ResolveRefs(s.Validation.Schema)
+		if err != nil {
+			return fmt.Errorf("failed to resolve references of validation: %w", err)
+		}
+
+		s.Validation.Schema = schema
+	}
+
+	return nil
+}
+
+// ResolveRefs returns a copy of schema in which local references to #/definitions/... are replaced by
+// the referenced definitions. Definitions are visible to the schema defining them and all schemas below it.
+func ResolveRefs(schema *v1beta1.JSONSchemaProps) (*v1beta1.JSONSchemaProps, error) {
+	return (&RefResolver{Prefix: definitionsPrefix}).Resolve(schema)
+}
+
+// Resolve returns a copy of schema in which the references are replaced by the referenced definitions.
+func (r *RefResolver) Resolve(schema *v1beta1.JSONSchemaProps) (*v1beta1.JSONSchemaProps, error) {
+	return r.resolvePtr(schema, r.Definitions, nil)
+}
+
+// resolveRefs resolves the references in props. definitions are the definitions in scope and
+// stack contains the names of the definitions that are currently being resolved.
+func (r *RefResolver) resolveRefs(props v1beta1.JSONSchemaProps, definitions v1beta1.JSONSchemaDefinitions, stack []string) (v1beta1.JSONSchemaProps, error) {
+	if r.Normalize != nil {
+		props = r.Normalize(props)
+	}
+
+	if len(props.Definitions) > 0 {
+		definitions = maps.Clone(definitions)
+		if definitions == nil {
+			definitions = v1beta1.JSONSchemaDefinitions{}
+		}
+
+		maps.Copy(definitions, props.Definitions)
+		props.Definitions = nil
+	}
+
+	if props.Ref != nil && strings.HasPrefix(*props.Ref, r.Prefix) {
+		return r.resolveRef(props, definitions, stack)
+	}
+
+	var err error
+	if props.Properties, err = r.resolveMap(props.Properties, definitions, stack); err != nil {
+		return props, err
+	}
+
+	if props.PatternProperties, err = r.resolveMap(props.PatternProperties, definitions, stack); err != nil {
+		return props, err
+	}
+
+	if props.Items != nil {
+		items := &v1beta1.JSONSchemaPropsOrArray{}
+		if items.Schema, err = r.resolvePtr(props.Items.Schema, definitions, stack); err != nil {
+			return props, err
+		}
+
+		if items.JSONSchemas, err = r.resolveSlice(props.Items.JSONSchemas, definitions, stack); err != nil {
+			return props, err
+		}
+
+		props.Items = items
+	}
+
+	for _, field := range []**v1beta1.JSONSchemaPropsOrBool{&props.AdditionalProperties, &props.AdditionalItems} {
+		if *field == nil || (*field).Schema == nil {
+			continue
+		}
+
+		schema, err := r.resolvePtr((*field).Schema, definitions, stack)
+		if err != nil {
+			return props, err
+		}
+
+		*field = &v1beta1.JSONSchemaPropsOrBool{Allows: (*field).Allows, Schema: schema}
+	}
+
+	if props.Not, err = r.resolvePtr(props.Not, definitions, stack); err != nil {
+		return props, err
+	}
+
+	for _, field := range []*[]v1beta1.JSONSchemaProps{&props.AllOf, &props.OneOf, &props.AnyOf} {
+		if *field, err = r.resolveSlice(*field, definitions, stack); err != nil {
+			return props, err
+		}
+	}
+
+	return props, nil
+}
+
+// resolveRef replaces a reference with the referenced definition. The description, default and example
+// next to the reference take precedence over the ones of the definition.
+func (r *RefResolver) resolveRef(props v1beta1.JSONSchemaProps, definitions v1beta1.JSONSchemaDefinitions, stack []string) (v1beta1.JSONSchemaProps, error) {
+	name := strings.ReplaceAll(strings.ReplaceAll(strings.TrimPrefix(*props.Ref, r.Prefix), "~1", "/"), "~0", "~")
+
+	definition, ok := definitions[name]
+	if !ok {
+		if r.IgnoreMissing {
+			return unknownObject(props.Description), nil
+		}
+
+		return props, fmt.Errorf("definition %s referenced by %s not found", name, *props.Ref)
+	}
+
+	description := cmp.Or(props.Description, definition.Description)
+	if slices.Contains(stack, name) || len(stack) >= MaxRefDepth {
+		return unknownObject(description), nil
+	}
+
+	resolved, err := r.resolveRefs(definition, definitions, append(slices.Clip(stack), name))
+	if err != nil {
+		return props, err
+	}
+
+	resolved.Description = description
+	if props.Default != nil {
+		resolved.Default = props.Default
+	}
+
+	if props.Example != nil {
+		resolved.Example = props.Example
+	}
+
+	return resolved, nil
+}
+
+// unknownObject is used instead of schemas that can't be resolved.
+func unknownObject(description string) v1beta1.JSONSchemaProps {
+	preserve := true
+
+	return v1beta1.JSONSchemaProps{Type: "object", Description: description, XPreserveUnknownFields: &preserve}
+}
+
+func (r *RefResolver) resolvePtr(props *v1beta1.JSONSchemaProps, definitions v1beta1.JSONSchemaDefinitions, stack []string) (*v1beta1.JSONSchemaProps, error) {
+	if props == nil {
+		return nil, nil
+	}
+
+	resolved, err := r.resolveRefs(*props, definitions, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolved, nil
+}
+
+func (r *RefResolver) resolveSlice(schemas []v1beta1.JSONSchemaProps, definitions v1beta1.JSONSchemaDefinitions, stack []string) ([]v1beta1.JSONSchemaProps, error) {
+	if schemas == nil {
+		return nil, nil
+	}
+
+	resolved := make([]v1beta1.JSONSchemaProps, 0, len(schemas))
+	for _, s := range schemas {
+		res, err := r.resolveRefs(s, definitions, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved = append(resolved, res)
+	}
+
+	return resolved, nil
+}
+
+func (r *RefResolver) resolveMap(schemas map[string]v1beta1.JSONSchemaProps, definitions v1beta1.JSONSchemaDefinitions, stack []string) (map[string]v1beta1.JSONSchemaProps, error) {
+	if schemas == nil {
+		return nil, nil
+	}
+
+	resolved := make(map[string]v1beta1.JSONSchemaProps, len(schemas))
+	for k, s := range schemas {
+		res, err := r.resolveRefs(s, definitions, stack)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+
+		resolved[k] = res
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+func TestGenerateWithRefs(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "sample_crd_with_refs.yaml"))
+	require.NoError(t, err)
+
+	crd := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal(content, crd))
+	schemaType, err := ExtractSchemaType(crd)
+	require.NoError(t, err)
+
+	var output []byte
+	buffer := bytes.NewBuffer(output)
+	nopCloser := &WriteNoOpCloser{w: buffer}
+	require.NoError(t, Generate(schemaType, nopCloser, false, false, true))
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "sample_crd_with_refs_golden.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(golden), buffer.String())
+}
+
+func TestResolveRefs(t *testing.T) {
+	ref := func(s string) *string { return &s }
+
+	schema := &v1beta1.JSONSchemaProps{
+		Definitions: v1beta1.JSONSchemaDefinitions{
+			"a/b": {Description: "Escaped name.", Type: "string"},
+			"node": {
+				Description: "Node has children.",
+				Type:        "object",
+				Properties: map[string]v1beta1.JSONSchemaProps{
+					"child": {Ref: ref("#/definitions/node")},
+				},
+			},
+		},
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"escaped": {Ref: ref("#/definitions/a~1b"), Description: "Overridden."},
+			"node":    {Ref: ref("#/definitions/node")},
+			"remote":  {Ref: ref("https://example.com/schema.json")},
+		},
+	}
+
+	resolved, err := ResolveRefs(schema)
+	require.NoError(t, err)
+	assert.Nil(t, resolved.Definitions)
+
+	escaped := resolved.Properties["escaped"]
+	assert.Nil(t, escaped.Ref)
+	assert.Equal(t, "string", escaped.Type)
+	assert.Equal(t, "Overridden.", escaped.Description)
+
+	// the cyclic reference of child is resolved once.
+	node := resolved.Properties["node"]
+	assert.Equal(t, "Node has children.", node.Description)
+	child := node.Properties["child"]
+	assert.Empty(t, child.Properties)
+	require.NotNil(t, child.XPreserveUnknownFields)
+	assert.True(t, *child.XPreserveUnknownFields)
+
+	// references to other documents are left untouched.
+	assert.Equal(t, "https://example.com/schema.json", *resolved.Properties["remote"].Ref)
+
+	// the original schema isn't modified.
+	assert.NotNil(t, schema.Definitions)
+	assert.NotNil(t, schema.Properties["node"].Ref)
+
+	_, err = ResolveRefs(&v1beta1.JSONSchemaProps{
+		Properties: map[string]v1beta1.JSONSchemaProps{"missing": {Ref: ref("#/definitions/missing")}},
+	})
+	require.ErrorContains(t, err, "definition missing referenced by #/definitions/missing not found")
+}
+
+func TestRefResolver(t *testing.T) {
+	ref := func(s string) *string { return &s }
+
+	// a chain of definitions that is one reference longer than the maximum depth.
+	definitions := v1beta1.JSONSchemaDefinitions{}
+	for i := range MaxRefDepth + 1 {
+		definitions[fmt.Sprintf("d%d", i)] = v1beta1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]v1beta1.JSONSchemaProps{"next": {Ref: ref(fmt.Sprintf("#/components/schemas/d%d", i+1))}},
+		}
+	}
+
+	r := &RefResolver{
+		Prefix:        "#/components/schemas/",
+		Definitions:   definitions,
+		IgnoreMissing: true,
+		Normalize: func(props v1beta1.JSONSchemaProps) v1beta1.JSONSchemaProps {
+			props.Format = strings.TrimPrefix(props.Format, "x-")
+
+			return props
+		},
+	}
+
+	resolved, err := r.Resolve(&v1beta1.JSONSchemaProps{
+		Format: "x-date",
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"chain":   {Ref: ref("#/components/schemas/d0")},
+			"missing": {Ref: ref("#/components/schemas/missing"), Description: "Missing."},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "date", resolved.Format)
+
+	depth := 0
+	for node := resolved.Properties["chain"]; node.XPreserveUnknownFields == nil; node = node.Properties["next"] {
+		depth++
+	}
+	assert.Equal(t, MaxRefDepth, depth)
+
+	missing := resolved.Properties["missing"]
+	assert.Equal(t, "Missing.", missing.Description)
+	require.NotNil(t, missing.XPreserveUnknownFields)
+	assert.True(t, *missing.XPreserveUnknownFields)
+}
@@ -1,12 +1,47 @@
 package pkg
 
-import "github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+import (
+	"cmp"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
 
 // Rendering provides extra rendering information of this schema.
 type Rendering struct {
 	// Group defines which group this schema should belong to. If empty
 	// the schema's version will be used as grouping information.
 	Group string
+	// Description, Logo and Link describe the group. Logo and Link are URLs of an image and a page
+	// of the group.
+	Description string
+	Logo        string
+	Link        string
+	// Order is the position of the group. Groups are rendered in ascending order and groups with
+	// the same order in the order they are first seen in.
+	Order int
+	// Minimal, Comments and SkipRandom override the options this schema is rendered with if set.
+	Minimal    *bool
+	Comments   *bool
+	SkipRandom *bool
+	// Output is the location this schema is rendered to instead of the default output.
+	Output string
+}
+
+// Options returns opts with the options set by the rendering.
+func (r Rendering) Options(opts RenderOpts) RenderOpts {
+	if r.Minimal != nil {
+		opts.Minimal = *r.Minimal
+	}
+
+	if r.Comments != nil {
+		opts.Comments = *r.Comments
+	}
+
+	if r.SkipRandom != nil {
+		opts.Random = *r.SkipRandom
+	}
+
+	return opts
 }
 
 // SchemaType is a wrapper around any kind of object that provide the following:
@@ -21,17 +56,87 @@ type SchemaType struct {
 	Group      string
 	Kind       string
 
+	// Scope is either Namespaced or Cluster.
+	Scope      string
+	Plural     string
+	ShortNames []string
+	Categories []string
+	// Conversion is the conversion strategy of the CRD, None or Webhook.
+	Conversion string
+
+	// Location is the file, URL or path in a repository where this schema was loaded from.
+	Location string
+
 	Rendering Rendering
 }
 
+// VersionsOrValidation returns the versions of the schema. Old CRDs that only define a validation
+// for all versions return it as their only version, named by Validation.VersionName.
+func (s *SchemaType) VersionsOrValidation() []*CRDVersion {
+	if len(s.Versions) > 0 || s.Validation == nil {
+		return s.Versions
+	}
+
+	return []*CRDVersion{{
+		Name:           s.Validation.VersionName(),
+		Schema:         s.Validation.Schema,
+		PrinterColumns: s.Validation.PrinterColumns,
+		Subresources:   s.Validation.Subresources,
+	}}
+}
+
 // CRDVersion corresponds to a CRD version.
 type CRDVersion struct {
-	Name   string
-	Schema *v1beta1.JSONSchemaProps
+	Name           string
+	Schema         *v1beta1.JSONSchemaProps
+	PrinterColumns []PrinterColumn
+	Subresources   *Subresources
+	// Deprecated is true if the version is marked as deprecated. DeprecationWarning is the optional
+	// warning returned to clients using it.
+	Deprecated         bool
+	DeprecationWarning string
 }
 
 // Validation is a set of validation rules that should be applied to all versions.
 type Validation struct {
-	Name   string
-	Schema *v1beta1.JSONSchemaProps
+	Name           string
+	Schema         *v1beta1.JSONSchemaProps
+	PrinterColumns []PrinterColumn
+	Subresources   *Subresources
+	// Version is the spec.version of the CRD. It's empty if the CRD doesn't set it.
+	Version string
+}
+
+// DefaultValidationVersion is the version of CRDs that only define a validation but don't set
+// spec.version. Such CRDs are written for the apiextensions.k8s.io/v1beta1 API, whose kinds were
+// usually served as v1beta1 as well.
+const DefaultValidationVersion = "v1beta1"
+
+// VersionName returns the version the validation applies to, which is spec.version of the CRD or
+// DefaultValidationVersion if it isn't set.
+func (v *Validation) VersionName() string {
+	return cmp.Or(v.Version, DefaultValidationVersion)
+}
+
+// PrinterColumn is an additional column displayed by kubectl get.
+type PrinterColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	Priority    int32  `json:"priority,omitempty"`
+	JSONPath    string `json:"jsonPath"`
+}
+
+// Subresources defines the status and scale subresources of a version.
+type Subresources struct {
+	Status bool
+	Scale  *ScaleSubresource
+}
+
+// ScaleSubresource defines the paths used by the scale subresource.
+type ScaleSubresource struct {
+	SpecReplicasPath   string `json:"specReplicasPath"`
+	StatusReplicasPath string `json:"statusReplicasPath"`
+	LabelSelectorPath  string `json:"labelSelectorPath,omitempty"`
 }
@@ -0,0 +1,21 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+func TestVersionsOrValidation(t *testing.T) {
+	schema := &v1beta1.JSONSchemaProps{Type: "object"}
+
+	versions := []*CRDVersion{{Name: "v1", Schema: schema}}
+	assert.Equal(t, versions, (&SchemaType{Versions: versions}).VersionsOrValidation())
+
+	crd := &SchemaType{Validation: &Validation{Name: "v1beta1", Schema: schema, Subresources: &Subresources{Status: true}}}
+	assert.Equal(t, []*CRDVersion{{Name: "v1beta1", Schema: schema, Subresources: &Subresources{Status: true}}}, crd.VersionsOrValidation())
+
+	assert.Empty(t, (&SchemaType{}).VersionsOrValidation())
+}
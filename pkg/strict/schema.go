@@ -0,0 +1,65 @@
+package strict
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// Schema returns the JSON schema of the values Unmarshal accepts for t. Objects don't allow additional
+// properties and fields without omitempty are required. Descriptions are looked up by the name of the
+// type for objects and by Type.Field for their properties.
+func Schema(t reflect.Type, descriptions map[string]string) *v1beta1.JSONSchemaProps {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &v1beta1.JSONSchemaProps{
+			Type:                 "object",
+			Description:          descriptions[t.Name()],
+			Properties:           map[string]v1beta1.JSONSchemaProps{},
+			AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Allows: false},
+		}
+
+		for name, f := range fields(t) {
+			property := Schema(f.Type, descriptions)
+			if description := descriptions[f.owner+"."+f.Name]; description != "" {
+				property.Description = description
+			}
+
+			schema.Properties[name] = *property
+
+			if _, optional := tag(f.StructField); !optional {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+
+		slices.Sort(schema.Required)
+
+		return schema
+	case reflect.Map:
+		return &v1beta1.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: Schema(t.Elem(), descriptions)},
+		}
+	case reflect.Slice, reflect.Array:
+		return &v1beta1.JSONSchemaProps{
+			Type:  "array",
+			Items: &v1beta1.JSONSchemaPropsOrArray{Schema: Schema(t.Elem(), descriptions)},
+		}
+	case reflect.Bool:
+		return &v1beta1.JSONSchemaProps{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &v1beta1.JSONSchemaProps{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &v1beta1.JSONSchemaProps{Type: "number"}
+	case reflect.String:
+		return &v1beta1.JSONSchemaProps{Type: "string"}
+	default:
+		return &v1beta1.JSONSchemaProps{}
+	}
+}
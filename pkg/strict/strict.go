@@ -0,0 +1,217 @@
+// Package strict decodes YAML documents into structs rejecting fields that don't exist and generates
+// JSON schemas for them, so typos in configuration files are reported instead of silently ignored.
+package strict
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Unmarshal decodes the YAML or JSON content into v. Fields that don't exist in v and values of the
+// wrong kind are reported with the line they are defined on.
+func Unmarshal(content []byte, v any) error {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse content: %w", err)
+	}
+
+	var errs []error
+	check(&doc, reflect.TypeOf(v), "", &errs)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return yaml.Unmarshal(content, v)
+}
+
+// check walks the node and the type together and collects the fields that don't exist in t.
+func check(node *yamlv3.Node, t reflect.Type, path string, errs *[]error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		for _, n := range node.Content {
+			check(n, t, path, errs)
+		}
+
+		return
+	case yamlv3.AliasNode:
+		check(node.Alias, t, path, errs)
+
+		return
+	}
+
+	if node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if !expect(node, yamlv3.MappingNode, "an object", path, errs) {
+			return
+		}
+
+		known := fields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			// merge keys are resolved by the decoder.
+			if key.Tag == "!!merge" {
+				merged := []*yamlv3.Node{value}
+				if value.Kind == yamlv3.SequenceNode {
+					merged = value.Content
+				}
+
+				for _, n := range merged {
+					check(n, t, path, errs)
+				}
+
+				continue
+			}
+
+			f, ok := known[key.Value]
+			if !ok {
+				*errs = append(*errs, fmt.Errorf("line %d: unknown field %q%s", key.Line, key.Value, in(path)))
+
+				continue
+			}
+
+			check(value, f.Type, join(path, key.Value), errs)
+		}
+	case reflect.Map:
+		if !expect(node, yamlv3.MappingNode, "an object", path, errs) {
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			check(node.Content[i+1], t.Elem(), join(path, node.Content[i].Value), errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if !expect(node, yamlv3.SequenceNode, "a list", path, errs) {
+			return
+		}
+
+		for i, n := range node.Content {
+			check(n, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Interface:
+	default:
+		if expect(node, yamlv3.ScalarNode, "a value", path, errs) {
+			scalar(node, t, path, errs)
+		}
+	}
+}
+
+// scalar reports the node if its tag doesn't match the kind of t. Unquoted values like 30 or true
+// are tagged as numbers and booleans and have to be quoted to be used as strings.
+func scalar(node *yamlv3.Node, t reflect.Type, path string, errs *[]error) {
+	var (
+		name string
+		tags []string
+	)
+
+	switch t.Kind() {
+	case reflect.Bool:
+		name, tags = "a boolean", []string{"!!bool"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		name, tags = "an integer", []string{"!!int"}
+	case reflect.Float32, reflect.Float64:
+		name, tags = "a number", []string{"!!int", "!!float"}
+	case reflect.String:
+		name, tags = "a string", []string{"!!str", "!!timestamp"}
+	default:
+		return
+	}
+
+	if !slices.Contains(tags, node.ShortTag()) {
+		*errs = append(*errs, fmt.Errorf("line %d: expected %s%s, got %q", node.Line, name, in(path), node.Value))
+	}
+}
+
+// expect reports the node if it isn't of the kind.
+func expect(node *yamlv3.Node, kind yamlv3.Kind, name, path string, errs *[]error) bool {
+	if node.Kind == kind {
+		return true
+	}
+
+	*errs = append(*errs, fmt.Errorf("line %d: expected %s%s", node.Line, name, in(path)))
+
+	return false
+}
+
+// field is a field of a struct and the name of the struct that declares it.
+type field struct {
+	reflect.StructField
+	owner string
+}
+
+// fields returns the fields of the struct by the name they are encoded with. Fields of embedded
+// structs without a name are promoted.
+func fields(t reflect.Type) map[string]field {
+	result := map[string]field{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _ := tag(f)
+		if name == "-" {
+			continue
+		}
+
+		// like encoding/json, the fields of embedded structs are promoted even if the struct is unexported.
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				maps.Copy(result, fields(embedded))
+
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		result[name] = field{StructField: f, owner: t.Name()}
+	}
+
+	return result
+}
+
+// tag returns the name of the json tag of the field and whether it's optional.
+func tag(field reflect.StructField) (string, bool) {
+	name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+	return name, strings.Contains(","+options+",", ",omitempty,")
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func in(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	return " in " + path
+}
@@ -0,0 +1,197 @@
+package strict
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSource struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Retries *int              `json:"retries,omitempty"`
+	Timeout string            `json:"timeout,omitempty"`
+}
+
+type testBase struct {
+	Description string `json:"description,omitempty"`
+}
+
+type testGroup struct {
+	testBase
+
+	Name    string       `json:"name,omitempty"`
+	Folders []string     `json:"folders,omitempty"`
+	Sources []testSource `json:"sources,omitempty"`
+	Minimal bool         `json:"minimal,omitempty"`
+}
+
+type testConfig struct {
+	Groups []testGroup `json:"groups"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	content := []byte(`groups:
+  - name: infra
+    description: infrastructure
+    folders:
+      - crds
+    sources:
+      - url: https://example.com/crds.yaml
+        headers:
+          X-Api-Key: secret
+        retries: 5
+`)
+
+	config := &testConfig{}
+	require.NoError(t, Unmarshal(content, config))
+
+	retries := 5
+	assert.Equal(t, &testConfig{Groups: []testGroup{{
+		testBase: testBase{Description: "infrastructure"},
+		Name:     "infra",
+		Folders:  []string{"crds"},
+		Sources: []testSource{{
+			URL:     "https://example.com/crds.yaml",
+			Headers: map[string]string{"X-Api-Key": "secret"},
+			Retries: &retries,
+		}},
+	}}}, config)
+}
+
+func TestUnmarshalUnknownFields(t *testing.T) {
+	content := []byte(`groups:
+  - name: infra
+    folder:
+      - crds
+    sources:
+      - url: https://example.com/crds.yaml
+        retry: 5
+minimal: true
+`)
+
+	err := Unmarshal(content, &testConfig{})
+	require.Error(t, err)
+	assert.Equal(t, `line 3: unknown field "folder" in groups[0]
+line 7: unknown field "retry" in groups[0].sources[0]
+line 8: unknown field "minimal"`, err.Error())
+}
+
+func TestUnmarshalWrongKind(t *testing.T) {
+	content := []byte(`groups:
+  - name: infra
+    folders: crds
+    sources:
+      url: https://example.com/crds.yaml
+`)
+
+	err := Unmarshal(content, &testConfig{})
+	require.Error(t, err)
+	assert.Equal(t, `line 3: expected a list in groups[0].folders
+line 5: expected a list in groups[0].sources`, err.Error())
+}
+
+func TestUnmarshalWrongScalar(t *testing.T) {
+	content := []byte(`groups:
+  - name: infra
+    minimal: "yes"
+    sources:
+      - url: https://example.com/crds.yaml
+        timeout: 30
+        retries: many
+`)
+
+	err := Unmarshal(content, &testConfig{})
+	require.Error(t, err)
+	assert.Equal(t, `line 3: expected a boolean in groups[0].minimal, got "yes"
+line 6: expected a string in groups[0].sources[0].timeout, got "30"
+line 7: expected an integer in groups[0].sources[0].retries, got "many"`, err.Error())
+}
+
+func TestUnmarshalQuotedScalars(t *testing.T) {
+	content := []byte(`groups:
+  - name: "30"
+    sources:
+      - url: https://example.com/crds.yaml
+        timeout: "30s"
+        retries: 2
+`)
+
+	config := &testConfig{}
+	require.NoError(t, Unmarshal(content, config))
+	assert.Equal(t, "30", config.Groups[0].Name)
+	assert.Equal(t, "30s", config.Groups[0].Sources[0].Timeout)
+}
+
+func TestUnmarshalAnchors(t *testing.T) {
+	content := []byte(`groups:
+  - &infra
+    name: infra
+    folders:
+      - crds
+  - <<: *infra
+    name: apps
+    minimal: true
+`)
+
+	config := &testConfig{}
+	require.NoError(t, Unmarshal(content, config))
+	require.Len(t, config.Groups, 2)
+	assert.Equal(t, "apps", config.Groups[1].Name)
+	assert.Equal(t, []string{"crds"}, config.Groups[1].Folders)
+}
+
+func TestUnmarshalInvalidYAML(t *testing.T) {
+	err := Unmarshal([]byte("groups: [\n"), &testConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse content")
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema(reflect.TypeOf(testConfig{}), map[string]string{
+		"testConfig":           "Configuration.",
+		"testGroup.Name":       "Name of the group.",
+		"testBase.Description": "Description of the group.",
+	})
+
+	content, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "type": "object",
+  "description": "Configuration.",
+  "additionalProperties": false,
+  "required": ["groups"],
+  "properties": {
+    "groups": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "additionalProperties": false,
+        "properties": {
+          "name": {"type": "string", "description": "Name of the group."},
+          "description": {"type": "string", "description": "Description of the group."},
+          "folders": {"type": "array", "items": {"type": "string"}},
+          "minimal": {"type": "boolean"},
+          "sources": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "additionalProperties": false,
+              "required": ["url"],
+              "properties": {
+                "url": {"type": "string"},
+                "headers": {"type": "object", "additionalProperties": {"type": "string"}},
+                "retries": {"type": "integer"},
+                "timeout": {"type": "string"}
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`, string(content))
+}
@@ -0,0 +1,223 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/convert"
+	"github.com/Skarlso/crd-to-sample-yaml/v1beta1"
+)
+
+// Status is the outcome of validating a single object.
+type Status string
+
+const (
+	StatusValid   Status = "valid"
+	StatusInvalid Status = "invalid"
+	// StatusUnknown is used for objects for which no CRD was found.
+	StatusUnknown Status = "unknown"
+	// StatusSkipped is used for built-in kinds, which aren't defined by a CRD.
+	StatusSkipped Status = "skipped"
+)
+
+// builtinGroups are the API groups served by Kubernetes itself.
+var builtinGroups = map[string]struct{}{
+	"":                             {},
+	"admissionregistration.k8s.io": {},
+	"apiextensions.k8s.io":         {},
+	"apiregistration.k8s.io":       {},
+	"apps":                         {},
+	"authentication.k8s.io":        {},
+	"authorization.k8s.io":         {},
+	"autoscaling":                  {},
+	"batch":                        {},
+	"certificates.k8s.io":          {},
+	"coordination.k8s.io":          {},
+	"discovery.k8s.io":             {},
+	"events.k8s.io":                {},
+	"flowcontrol.apiserver.k8s.io": {},
+	"internal.apiserver.k8s.io":    {},
+	"networking.k8s.io":            {},
+	"node.k8s.io":                  {},
+	"policy":                       {},
+	"rbac.authorization.k8s.io":    {},
+	"resource.k8s.io":              {},
+	"scheduling.k8s.io":            {},
+	"storage.k8s.io":               {},
+	"storagemigration.k8s.io":      {},
+}
+
+// FieldError is a single validation error of a field.
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Result is the outcome of validating a single object.
+type Result struct {
+	File string `json:"file"`
+	// Document is the index of the document in the file, starting at 0.
+	Document   int          `json:"document"`
+	APIVersion string       `json:"apiVersion"`
+	Kind       string       `json:"kind"`
+	Name       string       `json:"name,omitempty"`
+	Status     Status       `json:"status"`
+	Errors     []FieldError `json:"errors,omitempty"`
+}
+
+// Validator validates objects against the schema of their CRD.
+type Validator struct {
+	crds       map[schema.GroupKind]*pkg.SchemaType
+	validators map[schema.GroupVersionKind]validation.SchemaValidator
+}
+
+// NewValidator creates a validator for objects of the given CRDs.
+func NewValidator(crds []*pkg.SchemaType) *Validator {
+	v := &Validator{
+		crds:       make(map[schema.GroupKind]*pkg.SchemaType, len(crds)),
+		validators: make(map[schema.GroupVersionKind]validation.SchemaValidator),
+	}
+
+	for _, crd := range crds {
+		v.crds[schema.GroupKind{Group: crd.Group, Kind: crd.Kind}] = crd
+	}
+
+	return v
+}
+
+// Validate decodes every YAML or JSON document in r and validates the objects in it. Documents
+// containing a List are validated item by item.
+func (v *Validator) Validate(file string, r io.Reader) ([]Result, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(r, pkg.DecoderBufferSize)
+
+	var results []Result
+	for document := 0; ; document++ {
+		content := map[string]any{}
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				return results, nil
+			}
+
+			return nil, fmt.Errorf("failed to decode document %d of %s: %w", document, file, err)
+		}
+
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		objects := []*unstructured.Unstructured{obj}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read list in document %d of %s: %w", document, file, err)
+			}
+
+			objects = objects[:0]
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		}
+
+		for _, o := range objects {
+			result, err := v.ValidateObject(o)
+			if err != nil {
+				return nil, fmt.Errorf("failed to validate document %d of %s: %w", document, file, err)
+			}
+
+			result.File = file
+			result.Document = document
+			results = append(results, result)
+		}
+	}
+}
+
+// ValidateObject validates a single object against the schema of its CRD. Objects of built-in
+// kinds are skipped unless a CRD was given for them.
+func (v *Validator) ValidateObject(obj *unstructured.Unstructured) (Result, error) {
+	result := Result{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Status:     StatusValid,
+	}
+
+	validator, err := v.validator(obj.GroupVersionKind())
+	if err != nil {
+		return result, err
+	}
+
+	if validator == nil {
+		result.Status = StatusUnknown
+		// objects without an apiVersion would otherwise be in the core group.
+		if obj.GetAPIVersion() == "" {
+			return result, nil
+		}
+
+		if _, ok := builtinGroups[obj.GroupVersionKind().Group]; ok {
+			result.Status = StatusSkipped
+		}
+
+		return result, nil
+	}
+
+	for _, e := range validation.ValidateCustomResource(nil, obj.UnstructuredContent(), validator) {
+		result.Status = StatusInvalid
+		result.Errors = append(result.Errors, FieldError{
+			Path:    "." + e.Field,
+			Message: e.ErrorBody(),
+		})
+	}
+
+	return result, nil
+}
+
+// validator returns the schema validator for a version of a CRD. It returns nil if there is no
+// CRD or the CRD doesn't have the version.
+func (v *Validator) validator(gvk schema.GroupVersionKind) (validation.SchemaValidator, error) {
+	if validator, ok := v.validators[gvk]; ok {
+		return validator, nil
+	}
+
+	crd, ok := v.crds[gvk.GroupKind()]
+	if !ok {
+		return nil, nil
+	}
+
+	var props *v1beta1.JSONSchemaProps
+	for _, version := range crd.Versions {
+		if version.Name == gvk.Version {
+			props = version.Schema
+		}
+	}
+
+	// the validation of CRDs without versions applies to all versions.
+	if len(crd.Versions) == 0 && crd.Validation != nil {
+		props = crd.Validation.Schema
+	}
+
+	if props == nil {
+		return nil, nil
+	}
+
+	internal, err := convert.ToInternalSchema(props)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, _, err := validation.NewSchemaValidator(internal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema for %s: %w", gvk, err)
+	}
+
+	v.validators[gvk] = validator
+
+	return validator, nil
+}
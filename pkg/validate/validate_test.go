@@ -0,0 +1,109 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Skarlso/crd-to-sample-yaml/pkg"
+)
+
+func TestValidate(t *testing.T) {
+	manifests := `apiVersion: example.com/v1
+kind: Example
+metadata:
+  name: valid
+spec:
+  replicas: 1
+---
+# only a comment
+---
+apiVersion: example.com/v1
+kind: Example
+metadata:
+  name: invalid
+spec:
+  replicas: "one"
+  names: [a, 1]
+---
+apiVersion: example.com/v2
+kind: Example
+metadata:
+  name: unknown-version
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: example.com/v1
+  kind: Example
+  metadata:
+    name: in-list
+  spec:
+    replicas: 2
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: built-in
+---
+kind: Example
+metadata:
+  name: missing-api-version
+`
+
+	content, err := os.ReadFile(filepath.Join("testdata", "crd.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+
+	results, err := NewValidator(crds).Validate("manifests.yaml", strings.NewReader(manifests))
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	assert.Equal(t, Result{
+		File: "manifests.yaml", Document: 0, APIVersion: "example.com/v1", Kind: "Example", Name: "valid", Status: StatusValid,
+	}, results[0])
+
+	assert.Equal(t, StatusInvalid, results[1].Status)
+	assert.Equal(t, 2, results[1].Document)
+	paths := make([]string, 0, len(results[1].Errors))
+	for _, e := range results[1].Errors {
+		paths = append(paths, e.Path)
+	}
+	assert.ElementsMatch(t, []string{".spec.replicas", ".spec.names[1]"}, paths)
+
+	assert.Equal(t, StatusUnknown, results[2].Status)
+	assert.Equal(t, 3, results[2].Document)
+
+	assert.Equal(t, StatusValid, results[3].Status)
+	assert.Equal(t, "in-list", results[3].Name)
+	assert.Equal(t, 4, results[3].Document)
+
+	assert.Equal(t, StatusSkipped, results[4].Status)
+	assert.Equal(t, "Deployment", results[4].Kind)
+
+	assert.Equal(t, StatusUnknown, results[5].Status)
+	assert.Equal(t, "missing-api-version", results[5].Name)
+}
+
+func TestValidateJSON(t *testing.T) {
+	manifests := `{"apiVersion": "example.com/v1", "kind": "Example", "metadata": {"name": "a"}, "spec": {"replicas": "one"}}`
+
+	content, err := os.ReadFile(filepath.Join("testdata", "crd.yaml"))
+	require.NoError(t, err)
+
+	crds, err := pkg.ExtractSchemaTypes(content)
+	require.NoError(t, err)
+
+	results, err := NewValidator(crds).Validate("manifest.json", strings.NewReader(manifests))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, StatusInvalid, results[0].Status)
+	require.Len(t, results[0].Errors, 1)
+	assert.Equal(t, ".spec.replicas", results[0].Errors[0].Path)
+}
@@ -108,6 +108,11 @@ type Version struct {
 	Properties  []*Property
 	Description string
 	Schema      map[string]v1beta1.JSONSchemaProps
+
+	// CRD is only set for the first version of a Kind to display CRD level information.
+	CRD            *pkg.SchemaType
+	PrinterColumns []pkg.PrinterColumn
+	Subresources   *pkg.Subresources
 }
 
 func (v *Version) generateYAMLDetails(comment bool, minimal bool) (string, error) {
@@ -163,8 +168,15 @@ func (h *crdView) OnNav(ctx app.Context) {
 		return
 	}
 
+	u, headers, err := contentURL(u, "")
+	if err != nil {
+		h.preRenderErr = err
+
+		return
+	}
+
 	// authentication is not available here.
-	f := fetcher.NewFetcher(http.DefaultClient, "", "", "")
+	f := fetcher.NewFetcher(http.DefaultClient, "", "", "").WithHeaders(headers)
 	content, err := f.Fetch(u)
 	if err != nil {
 		h.preRenderErr = err
@@ -190,22 +202,33 @@ func (h *crdView) Render() app.UI {
 
 	versions := make([]Version, 0)
 	for _, schemaType := range h.crds {
-		for _, version := range schemaType.Versions {
+		for i, version := range schemaType.Versions {
 			v, err := h.generate(schemaType, version.Schema, schemaType.Kind+"-"+version.Name)
 			if err != nil {
 				return h.buildError(err)
 			}
 
+			// the CRD level information is displayed once at the top of each Kind.
+			if i == 0 {
+				v.CRD = schemaType
+			}
+			v.PrinterColumns = version.PrinterColumns
+			v.Subresources = version.Subresources
+
 			versions = append(versions, v)
 		}
 
 		// Parse validation instead.
 		if len(schemaType.Versions) == 0 && schemaType.Validation != nil {
-			v, err := h.generate(schemaType, schemaType.Validation.Schema, schemaType.Kind+"-"+schemaType.Validation.Name)
+			v, err := h.generate(schemaType, schemaType.Validation.Schema, schemaType.Kind+"-"+schemaType.Validation.VersionName())
 			if err != nil {
 				return h.buildError(err)
 			}
 
+			v.CRD = schemaType
+			v.PrinterColumns = schemaType.Validation.PrinterColumns
+			v.Subresources = schemaType.Validation.Subresources
+
 			versions = append(versions, v)
 		}
 	}
@@ -243,6 +266,10 @@ func (h *crdView) Render() app.UI {
 				),
 			),
 		)
+		if version.CRD != nil {
+			div.Body(renderMetadata(version.CRD))
+		}
+
 		div.Body(
 			app.H1().Body(
 				app.P().Body(app.Text(fmt.Sprintf(
@@ -252,6 +279,8 @@ func (h *crdView) Render() app.UI {
 				))),
 				app.P().Body(app.Text("Kind: "+version.Kind))),
 			app.P().Body(app.Text(version.Description)),
+			renderSubresources(version.Subresources),
+			renderPrinterColumns(version.PrinterColumns),
 			app.P().Body(app.Text("Generated YAML sample:")),
 			yamlContent,
 			app.H1().Text(version.Version),
@@ -287,6 +316,77 @@ func (h *crdView) generate(crd *pkg.SchemaType, properties *v1beta1.JSONSchemaPr
 	}, nil
 }
 
+// renderMetadata displays the scope, names and conversion strategy of a CRD.
+func renderMetadata(crd *pkg.SchemaType) app.UI {
+	var rows []app.UI
+	addRow := func(name, value string) {
+		if value == "" {
+			return
+		}
+
+		rows = append(rows, app.Tr().Body(
+			app.Th().Scope("row").Text(name),
+			app.Td().Text(value),
+		))
+	}
+
+	addRow("Scope", crd.Scope)
+	addRow("Plural", crd.Plural)
+	addRow("Short names", strings.Join(crd.ShortNames, ", "))
+	addRow("Categories", strings.Join(crd.Categories, ", "))
+	addRow("Conversion strategy", crd.Conversion)
+
+	return app.Div().Body(
+		app.H2().Text(crd.Kind),
+		app.Table().Class("table table-sm").Body(app.TBody().Body(rows...)),
+	)
+}
+
+func renderSubresources(subresources *pkg.Subresources) app.UI {
+	if subresources == nil {
+		return app.Text("")
+	}
+
+	var names []string
+	if subresources.Status {
+		names = append(names, "status")
+	}
+	if subresources.Scale != nil {
+		names = append(names, fmt.Sprintf("scale (%s, %s)", subresources.Scale.SpecReplicasPath, subresources.Scale.StatusReplicasPath))
+	}
+
+	return app.P().Text("Subresources: " + strings.Join(names, ", "))
+}
+
+func renderPrinterColumns(columns []pkg.PrinterColumn) app.UI {
+	if len(columns) == 0 {
+		return app.Text("")
+	}
+
+	rows := make([]app.UI, 0, len(columns))
+	for _, c := range columns {
+		rows = append(rows, app.Tr().Body(
+			app.Td().Text(c.Name),
+			app.Td().Text(c.Type),
+			app.Td().Class("font-monospace").Text(c.JSONPath),
+			app.Td().Text(c.Description),
+		))
+	}
+
+	return app.Div().Body(
+		app.P().Text("Additional printer columns:"),
+		app.Table().Class("table table-sm").Body(
+			app.THead().Body(app.Tr().Body(
+				app.Th().Scope("col").Text("Name"),
+				app.Th().Scope("col").Text("Type"),
+				app.Th().Scope("col").Text("JSONPath"),
+				app.Th().Scope("col").Text("Description"),
+			)),
+			app.TBody().Body(rows...),
+		),
+	)
+}
+
 func render(d app.UI, p []*Property, accordionID string) app.UI {
 	elements := make([]app.UI, 0, len(p))
 	for _, prop := range p {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/Skarlso/crd-to-sample-yaml/pkg"
 	"github.com/Skarlso/crd-to-sample-yaml/pkg/fetcher"
+	"github.com/Skarlso/crd-to-sample-yaml/pkg/provider"
 	"github.com/Skarlso/crd-to-sample-yaml/pkg/sanitize"
 )
 
@@ -159,6 +160,10 @@ func renderCRDContent(content []byte) (*pkg.SchemaType, error) {
 		return nil, nil
 	}
 
+	if err := schemaType.ResolveRefs(); err != nil {
+		return nil, err
+	}
+
 	return schemaType, nil
 }
 
@@ -197,8 +202,15 @@ func (i *index) OnClick(ctx app.Context, _ app.Event) {
 		return
 	}
 
-	f := fetcher.NewFetcher(http.DefaultClient, username.String(), password.String(), token.String())
-	content, err := f.Fetch(inp.String())
+	u, headers, err := contentURL(inp.String(), token.String())
+	if err != nil {
+		i.err = err
+
+		return
+	}
+
+	f := fetcher.NewFetcher(http.DefaultClient, username.String(), password.String(), token.String()).WithHeaders(headers)
+	content, err := f.Fetch(u)
 	if err != nil {
 		i.err = fmt.Errorf("failed to fetch CRD content: %w", err)
 
@@ -283,6 +295,12 @@ func (e *editView) OnInput(ctx app.Context, _ app.Event) {
 		return
 	}
 
+	if err := schemaType.ResolveRefs(); err != nil {
+		e.content = []byte(err.Error())
+
+		return
+	}
+
 	e.content = nil
 
 	parser := pkg.NewParser(schemaType.Group, schemaType.Kind, false, false, false)
@@ -335,3 +353,24 @@ func (i *index) Render() app.UI {
 
 	return app.Main()
 }
+
+// contentURL returns the URL the content of u is fetched from. The web pages of files in GitHub and GitLab
+// repositories are replaced by the raw content or, if a token is set, the content served by the API.
+func contentURL(u, token string) (string, map[string]string, error) {
+	loc, ok := provider.Parse(u, provider.Providers)
+	if !ok {
+		return u, nil, nil
+	}
+
+	if loc.Tree {
+		return "", nil, errors.New("directories of repositories can't be loaded here, link a file of the directory instead")
+	}
+
+	if token != "" {
+		apiURL, headers := loc.APIURL()
+
+		return apiURL, headers, nil
+	}
+
+	return loc.RawURL(), nil, nil
+}